@@ -0,0 +1,237 @@
+package margaid
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/erkkah/margaid/svg"
+)
+
+// HLine draws a horizontal reference line across the plotting area
+// at the given value on a y axis.
+// The line is gray and 1px wide by default, see UsingColor, UsingDash
+// and UsingStrokeWidth.
+func (m *Margaid) HLine(axis Axis, value float64, using ...Using) {
+	if m.record(func() { m.HLine(axis, value, using...) }) {
+		return
+	}
+
+	if m.notPolar("reference lines") {
+		return
+	}
+
+	if axis != Y1Axis && axis != Y2Axis {
+		m.error("HLine needs a y axis")
+		return
+	}
+	m.referenceLine(axis, value, using)
+}
+
+// VLine draws a vertical reference line across the plotting area
+// at the given value on an x axis. See HLine for options.
+func (m *Margaid) VLine(axis Axis, value float64, using ...Using) {
+	if m.record(func() { m.VLine(axis, value, using...) }) {
+		return
+	}
+
+	if m.notPolar("reference lines") {
+		return
+	}
+
+	if axis != X1Axis && axis != X2Axis {
+		m.error("VLine needs an x axis")
+		return
+	}
+	m.referenceLine(axis, value, using)
+}
+
+// referenceLine draws a line across the plotting area at a value,
+// perpendicular to the axis.
+func (m *Margaid) referenceLine(axis Axis, value float64, using []Using) {
+	options := getPlotOptions(append([]Using{
+		UsingColor("gray"),
+		UsingStrokeWidth(1),
+	}, using...))
+
+	position, err := m.project(value, axis)
+	if err != nil {
+		m.error(err.Error())
+		return
+	}
+
+	plotWidth := m.plotWidth()
+	plotHeight := m.plotHeight()
+
+	line := []struct{ X, Y float64 }{
+		{0, position},
+		{plotWidth, position},
+	}
+	if axis == X1Axis || axis == X2Axis {
+		line = []struct{ X, Y float64 }{
+			{position, 0},
+			{position, plotHeight},
+		}
+	}
+
+	m.g.
+		StrokeWidth(fmt.Sprintf("%vpx", options.strokeWidth)).
+		Fill("none").
+		Stroke(options.color).
+		Dash(options.dash).
+		Transform(
+			svg.Translation(m.insets.left, m.height-m.insets.bottom),
+			svg.Scaling(1, -1),
+		).
+		Clip(m.plotClip()).
+		Polyline(line...).
+		Dash("").
+		Transform().
+		Clip("")
+}
+
+// Region shades the part of the plotting area between two values on an
+// axis, using the given color as a valid SVG color attribute string.
+// Regions on x axes span the full plot height, and regions on y axes
+// span the full plot width.
+func (m *Margaid) Region(axis Axis, from, to float64, color string) {
+	if m.record(func() { m.Region(axis, from, to, color) }) {
+		return
+	}
+
+	if m.notPolar("regions") {
+		return
+	}
+
+	start, err := m.project(from, axis)
+	if err != nil {
+		m.error(err.Error())
+		return
+	}
+	end, err := m.project(to, axis)
+	if err != nil {
+		m.error(err.Error())
+		return
+	}
+	if end < start {
+		start, end = end, start
+	}
+
+	plotWidth := m.plotWidth()
+	plotHeight := m.plotHeight()
+
+	m.g.
+		Fill(color).
+		Stroke("none").
+		Transform(
+			svg.Translation(m.insets.left, m.height-m.insets.bottom),
+			svg.Scaling(1, -1),
+		).
+		Clip(m.plotClip())
+
+	if axis == X1Axis || axis == X2Axis {
+		m.g.Rect(start, 0, end-start, plotHeight)
+	} else {
+		m.g.Rect(0, start, plotWidth, end-start)
+	}
+	m.g.Transform().
+		Clip("")
+}
+
+// Annotate places a text label at the point x, y on the axes selected by
+// UsingAxes. Newlines in the text break the label into multiple lines.
+// The label is placed right above the point, unless UsingArrow is
+// specified. The label is black by default, see UsingColor.
+func (m *Margaid) Annotate(x, y float64, text string, using ...Using) {
+	if m.record(func() { m.Annotate(x, y, text, using...) }) {
+		return
+	}
+
+	options := getPlotOptions(append([]Using{
+		UsingColor("black"),
+		UsingStrokeWidth(1),
+	}, using...))
+
+	points, err := m.projectValues([]Value{MakeValue(x, y)}, options.xAxis, options.yAxis)
+	if err != nil {
+		m.error(err.Error())
+		return
+	}
+
+	// Canvas coordinates
+	pointX := m.insets.left + points[0].X
+	pointY := m.height - m.insets.bottom - points[0].Y
+
+	labelX := pointX
+	labelY := pointY - textSpacing
+	hAlignment := svg.HAlignMiddle
+	vAlignment := svg.VAlignBottom
+
+	if options.arrow != nil {
+		dx := options.arrow.X
+		dy := options.arrow.Y
+		labelX = pointX + dx
+		labelY = pointY + dy
+
+		switch {
+		case dx > 0:
+			hAlignment = svg.HAlignStart
+		case dx < 0:
+			hAlignment = svg.HAlignEnd
+		}
+		switch {
+		case dy > 0:
+			vAlignment = svg.VAlignTop
+		case dy < 0:
+			vAlignment = svg.VAlignBottom
+		default:
+			vAlignment = svg.VAlignCentral
+		}
+
+		length := math.Hypot(dx, dy)
+		if length > textSpacing {
+			// Unit vector from the label to the point
+			ux := -dx / length
+			uy := -dy / length
+			const headLength = 8
+			const headWidth = 3.5
+
+			start := struct{ X, Y float64 }{labelX + ux*textSpacing, labelY + uy*textSpacing}
+			base := struct{ X, Y float64 }{pointX - ux*headLength, pointY - uy*headLength}
+
+			m.g.
+				Transform().
+				StrokeWidth(fmt.Sprintf("%vpx", options.strokeWidth)).
+				Fill("none").
+				Stroke(options.color).
+				Dash(options.dash).
+				Polyline(start, base).
+				Dash("").
+				Fill(options.color).
+				Polyline(
+					struct{ X, Y float64 }{pointX, pointY},
+					struct{ X, Y float64 }{base.X - uy*headWidth, base.Y + ux*headWidth},
+					struct{ X, Y float64 }{base.X + uy*headWidth, base.Y - ux*headWidth},
+					struct{ X, Y float64 }{pointX, pointY},
+				)
+		}
+	}
+
+	// Lines flow downwards from the first one, so labels above or
+	// beside the point are lifted by the height of the extra lines
+	lines := float64(strings.Count(text, "\n"))
+	switch vAlignment {
+	case svg.VAlignBottom:
+		labelY -= lines * float64(m.labelSize)
+	case svg.VAlignCentral:
+		labelY -= lines * float64(m.labelSize) / 2
+	}
+
+	m.g.
+		Transform().
+		Font(m.labelFamily, fmt.Sprintf("%dpx", m.labelSize)).
+		FontStyle(svg.StyleNormal, svg.WeightNormal).
+		Alignment(hAlignment, vAlignment).
+		Fill(options.color).
+		Text(labelX, labelY, svg.EncodeTextFor(text, hAlignment, m.labelFamily))
+}
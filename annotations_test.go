@@ -0,0 +1,125 @@
+package margaid
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/erkkah/margaid/xt"
+)
+
+func render(m *Margaid) string {
+	var rendered strings.Builder
+	m.Render(&rendered)
+	return rendered.String()
+}
+
+func TestReferenceLines(t *testing.T) {
+	x := xt.X(t)
+
+	m := New(100, 100, WithInset(0), WithRange(XAxis, 0, 10), WithRange(YAxis, 0, 10))
+	m.HLine(YAxis, 5)
+	m.VLine(XAxis, 2)
+	rendered := render(m)
+
+	x.Assert(strings.Contains(rendered, `d="M0,50 L100,50 "`), rendered)
+	x.Assert(strings.Contains(rendered, `d="M20,0 L20,100 "`), rendered)
+	x.False(strings.Contains(rendered, "needs"))
+}
+
+func TestReferenceLineAxes(t *testing.T) {
+	x := xt.X(t)
+
+	m := New(100, 100)
+	m.HLine(XAxis, 5)
+	x.Assert(strings.Contains(render(m), "HLine needs a y axis"))
+
+	m = New(100, 100)
+	m.VLine(Y2Axis, 5)
+	x.Assert(strings.Contains(render(m), "VLine needs an x axis"))
+}
+
+func TestRegion(t *testing.T) {
+	x := xt.X(t)
+
+	m := New(100, 100, WithInset(0), WithRange(XAxis, 0, 100), WithRange(YAxis, 0, 100))
+	m.Region(XAxis, 50, 20, "red")
+	m.Region(YAxis, 10, 30, "blue")
+	rendered := render(m)
+
+	x.Assert(strings.Contains(rendered, `<rect height="100" vector-effect="non-scaling-stroke" width="30" x="20" y="0"/>`), rendered)
+	x.Assert(strings.Contains(rendered, `<rect height="20" vector-effect="non-scaling-stroke" width="100" x="0" y="10"/>`), rendered)
+}
+
+func annotated(text string, using ...Using) string {
+	m := New(100, 100, WithInset(0), WithRange(XAxis, 0, 100), WithRange(YAxis, 0, 100))
+	m.Annotate(50, 50, text, using...)
+	return render(m)
+}
+
+func TestAnnotate(t *testing.T) {
+	x := xt.X(t)
+
+	// Right above the point, without an arrow
+	rendered := annotated("label")
+	x.Assert(strings.Contains(rendered, `text-anchor="middle"`), rendered)
+	x.Assert(strings.Contains(rendered, `x="50" y="46">label</text>`), rendered)
+	x.False(strings.Contains(rendered, "<path"))
+}
+
+func TestAnnotateAlignment(t *testing.T) {
+	x := xt.X(t)
+
+	for _, c := range []struct {
+		dx, dy   float64
+		anchor   string
+		baseline string
+	}{
+		{20, 20, "start", "hanging"},
+		{20, 0, "start", "middle"},
+		{20, -20, "start", "baseline"},
+		{0, 20, "middle", "hanging"},
+		{0, -20, "middle", "baseline"},
+		{-20, 20, "end", "hanging"},
+		{-20, 0, "end", "middle"},
+		{-20, -20, "end", "baseline"},
+	} {
+		rendered := annotated("label", UsingArrow(c.dx, c.dy))
+		x.Assert(strings.Contains(rendered, `text-anchor="`+c.anchor+`"`), c)
+		x.Assert(strings.Contains(rendered, `dominant-baseline="`+c.baseline+`"`), c)
+
+		position := fmt.Sprintf(`x="%v" y="%v">label<`, 50+c.dx, 50+c.dy)
+		x.Assert(strings.Contains(rendered, position), c, rendered)
+	}
+}
+
+func TestAnnotateArrow(t *testing.T) {
+	x := xt.X(t)
+
+	rendered := annotated("label", UsingArrow(20, 0))
+	// The shaft runs from the label to the base of the head,
+	// and the head ends at the point
+	x.Assert(strings.Contains(rendered, `d="M66,50 L58,50 "`), rendered)
+	x.Assert(strings.Contains(rendered, `d="M50,50 L58,4.650000e+01 L58,5.350000e+01 L50,50 "`), rendered)
+
+	// No arrow when the label is right at the point
+	rendered = annotated("label", UsingArrow(2, 0))
+	x.False(strings.Contains(rendered, "<path"))
+	x.Assert(strings.Contains(rendered, `x="52" y="50">label<`), rendered)
+}
+
+func TestAnnotateMultiLine(t *testing.T) {
+	x := xt.X(t)
+
+	// Labels above the point are lifted to keep the last line above it
+	rendered := annotated("one\ntwo")
+	x.Assert(strings.Contains(rendered, `x="50" y="34">one<tspan`), rendered)
+	x.Assert(strings.Contains(rendered, `dy="1em">two</tspan>`), rendered)
+
+	rendered = annotated("one\ntwo", UsingArrow(20, 0))
+	x.Assert(strings.Contains(rendered, `x="70" y="44">one<tspan`), rendered)
+
+	// Labels below the point flow downwards from it
+	rendered = annotated("one\ntwo", UsingArrow(0, 20))
+	x.Assert(strings.Contains(rendered, `x="50" y="70">one<tspan`), rendered)
+}
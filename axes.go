@@ -2,6 +2,8 @@ package margaid
 
 import (
 	"fmt"
+	"math"
+	"unicode/utf8"
 
 	"github.com/erkkah/margaid/svg"
 )
@@ -22,8 +24,17 @@ const (
 
 // Axis draws tick marks and labels using the specified ticker
 func (m *Margaid) Axis(series *Series, axis Axis, ticker Ticker, grid bool, title string) {
-	var xOffset = m.inset
-	var yOffset = m.inset
+	if m.record(func() { m.Axis(series, axis, ticker, grid, title) }) {
+		return
+	}
+
+	if m.polar {
+		m.polarAxis(series, axis, ticker, grid, title)
+		return
+	}
+
+	var xOffset = m.insets.left
+	var yOffset = m.insets.top
 	var axisLength float64
 	var crossLength float64
 	var xMult float64
@@ -38,16 +49,16 @@ func (m *Margaid) Axis(series *Series, axis Axis, ticker Ticker, grid bool, titl
 	max := m.ranges[axis].max
 
 	xAttributes := func() {
-		axisLength = m.width - 2*m.inset
-		crossLength = m.height - 2*m.inset
+		axisLength = m.plotWidth()
+		crossLength = m.plotHeight()
 		xMult = 1
 		hAlignment = svg.HAlignMiddle
 	}
 
 	yAttributes := func() {
-		yOffset = m.height - m.inset
-		axisLength = m.height - 2*m.inset
-		crossLength = m.width - 2*m.inset
+		yOffset = m.height - m.insets.bottom
+		axisLength = m.plotHeight()
+		crossLength = m.plotWidth()
 		yMult = 1
 		vAlignment = svg.VAlignCentral
 		axisLabelRotation = -90.0
@@ -56,7 +67,7 @@ func (m *Margaid) Axis(series *Series, axis Axis, ticker Ticker, grid bool, titl
 	switch axis {
 	case X1Axis:
 		xAttributes()
-		yOffset = m.height - m.inset
+		yOffset = m.height - m.insets.bottom
 		tickSign = -1
 		vAlignment = svg.VAlignTop
 		axisLabelAlignment = svg.VAlignBottom
@@ -72,7 +83,7 @@ func (m *Margaid) Axis(series *Series, axis Axis, ticker Ticker, grid bool, titl
 		axisLabelAlignment = svg.VAlignTop
 	case Y2Axis:
 		yAttributes()
-		xOffset = m.width - m.inset
+		xOffset = m.width - m.insets.right
 		hAlignment = svg.HAlignStart
 		axisLabelAlignment = svg.VAlignBottom
 		axisLabelSign = -1
@@ -81,25 +92,73 @@ func (m *Margaid) Axis(series *Series, axis Axis, ticker Ticker, grid bool, titl
 	steps := axisLength / tickDistance
 	start := ticker.start(axis, series, int(steps))
 
+	var ticks []float64
+	for tick, hasMore := start, true; tick <= max && hasMore; tick, hasMore = ticker.next(tick) {
+		ticks = append(ticks, tick)
+	}
+
+	var minorTicks []float64
+	if minor, ok := m.minorTicks[axis]; ok {
+		ticks, minorTicks = m.getMinorTicks(axis, ticks, minor.subdivisions)
+	}
+
+	// line draws a line across the axis at a tick value,
+	// from the axis and the given length inwards.
+	line := func(tick float64, length float64) {
+		value, err := m.project(tick, axis)
+		if err == nil {
+			m.g.Polyline([]struct{ X, Y float64 }{
+				{value * xMult, value * yMult},
+				{value*xMult + tickSign*length*(yMult), value*yMult + tickSign*(xMult)*length},
+			}...)
+		}
+	}
+
+	if grid {
+		m.g.Transform(
+			svg.Translation(xOffset, yOffset),
+			svg.Scaling(1, -1),
+		)
+
+		if minor := m.minorTicks[axis]; minor.grid {
+			m.setGridStyle(m.minorGridStyles[axis])
+			for _, tick := range minorTicks {
+				line(tick, -crossLength)
+			}
+		}
+
+		m.setGridStyle(m.gridStyles[axis])
+		for _, tick := range ticks {
+			line(tick, -crossLength)
+		}
+		m.g.Dash("")
+	}
+
 	m.g.Transform(
 		svg.Translation(xOffset, yOffset),
 		svg.Scaling(1, -1),
 	).
-		StrokeWidth("2px").
+		StrokeWidth("1px").
 		Stroke("black")
 
-	var tick float64
-	var hasMore = true
+	for _, tick := range minorTicks {
+		line(tick, tickSize/2)
+	}
 
-	for tick = start; tick <= max && hasMore; tick, hasMore = ticker.next(tick) {
-		value, err := m.project(tick, axis)
+	m.g.StrokeWidth("2px")
 
-		if err == nil {
-			m.g.Polyline([]struct{ X, Y float64 }{
-				{value * xMult, value * yMult},
-				{value*xMult + tickSign*tickSize*(yMult), value*yMult + tickSign*(xMult)*tickSize},
-			}...)
+	for _, tick := range ticks {
+		line(tick, tickSize)
+	}
+
+	style := m.labels[axis]
+	if style.rotation != 0 {
+		// Rotated labels end at the tick on the near side of the axis
+		hAlignment = svg.HAlignEnd
+		if axis == X2Axis || axis == Y2Axis {
+			hAlignment = svg.HAlignStart
 		}
+		vAlignment = svg.VAlignCentral
 	}
 
 	m.g.Transform(
@@ -111,21 +170,94 @@ func (m *Margaid) Axis(series *Series, axis Axis, ticker Ticker, grid bool, titl
 		Alignment(hAlignment, vAlignment).
 		Fill("black")
 
-	lastLabel := -m.inset
 	textOffset := float64(tickSize + textSpacing)
-	hasMore = true
 
-	for tick = start; tick <= max && hasMore; tick, hasMore = ticker.next(tick) {
+	var labels []tickLabel
+	for _, tick := range ticks {
 		value, err := m.project(tick, axis)
-
 		if err == nil {
-			if value-lastLabel > float64(m.labelSize) {
-				m.g.Text(
-					value*xMult+(tickSign)*textOffset*(yMult),
-					-value*yMult+(-tickSign)*textOffset*(xMult),
-					ticker.label(tick))
-				lastLabel = value
+			labels = append(labels, tickLabel{
+				text: ticker.label(tick),
+				x:    value*xMult + (tickSign)*textOffset*(yMult),
+				y:    -value*yMult + (-tickSign)*textOffset*(xMult),
+			})
+		}
+	}
+
+	// box returns the bounding box of a label in axis coordinates
+	box := func(l tickLabel) (left, top, right, bottom float64) {
+		left, top, right, bottom = textBox(l.x, l.y, l.text, m.labelFamily, m.labelSize, hAlignment, vAlignment)
+		return rotatedBox(left, top, right, bottom, l.x, l.y, style.rotation)
+	}
+
+	// extent returns the extent of a label along and across the axis
+	extent := func(l tickLabel) (low, high, across float64) {
+		left, top, right, bottom := box(l)
+		if yMult != 0 {
+			return top, bottom, right - left
+		}
+		return left, right, bottom - top
+	}
+
+	if style.overlap == TruncateLabels && len(labels) > 1 {
+		spacing := math.Inf(1)
+		for i := 1; i < len(labels); i++ {
+			distance := math.Abs(labels[i].x-labels[i-1].x) + math.Abs(labels[i].y-labels[i-1].y)
+			spacing = math.Min(spacing, distance)
+		}
+		for i := range labels {
+			original := labels[i].text
+			for length := utf8.RuneCountInString(original) - 1; length > 0; length-- {
+				low, high, _ := extent(labels[i])
+				if high-low+textSpacing <= spacing {
+					break
+				}
+				labels[i].text = truncateText(original, length)
+			}
+		}
+	}
+
+	rows := 1
+	rowDistance := 0.0
+	if style.overlap == StaggerLabels {
+		rows = 2
+		for _, l := range labels {
+			_, _, across := extent(l)
+			rowDistance = math.Max(rowDistance, across+textSpacing)
+		}
+	}
+
+	// The extent along the axis of the last drawn label of each row,
+	// used for skipping labels that would overlap.
+	lastLow := []float64{math.Inf(1), math.Inf(1)}
+	lastHigh := []float64{math.Inf(-1), math.Inf(-1)}
+
+	for _, l := range labels {
+		for row := 0; row < rows; row++ {
+			// Rows are stacked outwards from the axis
+			l.x += float64(row) * rowDistance * tickSign * yMult
+			l.y -= float64(row) * rowDistance * tickSign * xMult
+
+			low, high, _ := extent(l)
+			if high+textSpacing > lastLow[row] && low-textSpacing < lastHigh[row] {
+				continue
+			}
+			lastLow[row], lastHigh[row] = low, high
+
+			if style.rotation != 0 {
+				m.g.Transform(
+					svg.Translation(xOffset, yOffset),
+					svg.Scaling(1, 1),
+					svg.Rotation(-style.rotation, l.x, l.y),
+				)
 			}
+			m.g.Text(l.x, l.y, svg.EncodeTextFor(l.text, hAlignment, m.labelFamily))
+
+			left, top, right, bottom := box(l)
+			space := m.overhang(xOffset+left, yOffset+top, xOffset+right, yOffset+bottom)
+			m.reserve(space)
+			m.labelSpace = m.labelSpace.max(space)
+			break
 		}
 	}
 
@@ -146,25 +278,247 @@ func (m *Margaid) Axis(series *Series, axis Axis, ticker Ticker, grid bool, titl
 			x, y, title,
 		)
 	}
+}
+
+// setGridStyle sets the current stroke to a grid style
+func (m *Margaid) setGridStyle(style GridStyle) {
+	m.g.StrokeWidth(fmt.Sprintf("%vpx", style.Width)).
+		Stroke(style.Color).
+		Dash(style.Dash)
+}
+
+// getMinorTicks splits a list of tick values into major and minor ticks,
+// adding minor ticks between the major ones.
+// On log axes, the major ticks are at whole powers of ten and the minor ticks
+// at the multiples of each power in between, like 2, 3, .., 9.
+// Elsewhere, the space between the major ticks is split into subdivisions.
+func (m *Margaid) getMinorTicks(axis Axis, ticks []float64, subdivisions int) (major, minor []float64) {
+	projection := m.projections[axis]
+	axisRange := m.ranges[axis]
+
+	isDecade := func(value float64) bool {
+		exponent := math.Log10(math.Abs(value))
+		return math.Abs(exponent-math.Round(exponent)) < 1e-9
+	}
+
+	if projection == Log {
+		for _, tick := range ticks {
+			if isDecade(tick) {
+				major = append(major, tick)
+			}
+		}
+		if len(major) < 2 {
+			// Too few decades to be useful as major ticks
+			major = ticks
+		}
+	} else {
+		major = ticks
+	}
+
+	if len(major) < 2 {
+		return major, nil
+	}
+
+	// Add one interval beyond the first and last major tick,
+	// to cover the full axis range.
+	first := major[0]
+	last := major[len(major)-1]
+	before := first - (major[1] - first)
+	after := last + (last - major[len(major)-2])
+	if projection == Log {
+		before, after = first/10, last*10
+	}
+	if projection == SymLog {
+		// Step one decade away from zero below the first and above the last tick
+		if isDecade(first) {
+			before = first / 10
+			if first < 0 {
+				before = first * 10
+			}
+		}
+		if isDecade(last) {
+			after = last * 10
+			if last < 0 {
+				after = last / 10
+			}
+		}
+	}
+	intervals := append(append([]float64{before}, major...), after)
+
+	isMajor := map[float64]bool{}
+	for _, tick := range major {
+		isMajor[tick] = true
+	}
+
+	for i := 1; i < len(intervals); i++ {
+		low := intervals[i-1]
+		high := intervals[i]
+
+		var between []float64
+		logInterval := low*high > 0 && isDecade(low) && isDecade(high) &&
+			math.Abs(math.Abs(math.Log10(high/low))-1) < 1e-9
+		switch {
+		case (projection == Log || projection == SymLog) && logInterval && low > 0:
+			for k := 2.0; k < 10; k++ {
+				between = append(between, low*k)
+			}
+		case (projection == Log || projection == SymLog) && logInterval && low < 0:
+			for k := 2.0; k < 10; k++ {
+				between = append(between, high*k)
+			}
+		case projection != Log && subdivisions > 1:
+			for k := 1; k < subdivisions; k++ {
+				between = append(between, low+(high-low)*float64(k)/float64(subdivisions))
+			}
+		}
+
+		for _, tick := range between {
+			if tick >= axisRange.min && tick <= axisRange.max && !isMajor[tick] {
+				minor = append(minor, tick)
+			}
+		}
+	}
+
+	return major, minor
+}
+
+// tickLabel is the text and position of a tick label in axis coordinates
+type tickLabel struct {
+	text string
+	x, y float64
+}
+
+// polarAxis draws tick marks, labels and grid lines in polar coordinates.
+// X axis ticks are placed around the circle, with grid lines as spokes.
+// Y axis ticks are placed along the top spoke, with grid lines as circles.
+func (m *Margaid) polarAxis(series *Series, axis Axis, ticker Ticker, grid bool, title string) {
+	cx, cy, radius := m.polarCircle()
+	cx += m.insets.left
+	cy = m.height - m.insets.bottom - cy
+
+	angular := axis == X1Axis || axis == X2Axis
+	plotWidth := m.plotWidth()
+	plotHeight := m.plotHeight()
+
+	steps := radius / tickDistance
+	if angular {
+		steps = 2 * math.Pi * radius / tickDistance
+	}
+	start := ticker.start(axis, series, int(steps))
+	max := m.ranges[axis].max
+
+	type polarTick struct {
+		value    float64
+		position float64 // angle in radians, or distance from center
+	}
+	var ticks []polarTick
+
+	var tick float64
+	var hasMore = true
+
+	for tick = start; tick <= max && hasMore; tick, hasMore = ticker.next(tick) {
+		value, err := m.project(tick, axis)
+		if err != nil {
+			continue
+		}
+		if angular {
+			angle := 2 * math.Pi * value / plotWidth
+			if angle > 2*math.Pi-1e-9 && len(ticks) > 0 && ticks[0].position < 1e-9 {
+				// Full turn, same position as the first tick
+				continue
+			}
+			ticks = append(ticks, polarTick{tick, angle})
+		} else {
+			ticks = append(ticks, polarTick{tick, radius * value / plotHeight})
+		}
+	}
+
+	// point returns the canvas position at a distance from the center,
+	// at an angle clockwise from the top
+	point := func(distance, angle float64) struct{ X, Y float64 } {
+		return struct{ X, Y float64 }{
+			cx + distance*math.Sin(angle),
+			cy - distance*math.Cos(angle),
+		}
+	}
 
 	if grid {
-		m.g.Transform(
-			svg.Translation(xOffset, yOffset),
-			svg.Scaling(1, -1),
-		).
-			StrokeWidth("0.5px").Stroke("gray")
+		m.g.Transform().
+			Fill("none")
+		m.setGridStyle(m.gridStyles[axis])
+
+		for _, t := range ticks {
+			if angular {
+				m.g.Polyline(point(0, 0), point(radius, t.position))
+			} else {
+				m.g.Circle(cx, cy, t.position)
+			}
+		}
+		m.g.Dash("")
+	}
+
+	m.g.Transform().
+		StrokeWidth("2px").
+		Stroke("black")
 
-		hasMore = true
+	for _, t := range ticks {
+		if angular {
+			m.g.Polyline(point(radius, t.position), point(radius+tickSize, t.position))
+		} else {
+			m.g.Polyline(point(t.position, 0), struct{ X, Y float64 }{cx - tickSize, cy - t.position})
+		}
+	}
+
+	m.g.
+		Font(m.labelFamily, fmt.Sprintf("%dpx", m.labelSize)).
+		FontStyle(svg.StyleNormal, svg.WeightNormal).
+		Fill("black")
 
-		for tick = start; tick <= max && hasMore; tick, hasMore = ticker.next(tick) {
-			value, err := m.project(tick, axis)
+	textOffset := float64(tickSize + textSpacing)
 
-			if err == nil {
-				m.g.Polyline([]struct{ X, Y float64 }{
-					{value * xMult, value * yMult},
-					{value*xMult - tickSign*crossLength*(yMult), value*yMult - tickSign*(xMult)*crossLength},
-				}...)
+	for _, t := range ticks {
+		if angular {
+			hAlignment := svg.HAlignMiddle
+			vAlignment := svg.VAlignCentral
+			sin := math.Sin(t.position)
+			cos := math.Cos(t.position)
+			switch {
+			case sin > 0.1:
+				hAlignment = svg.HAlignStart
+			case sin < -0.1:
+				hAlignment = svg.HAlignEnd
 			}
+			switch {
+			case cos > 0.1:
+				vAlignment = svg.VAlignBottom
+			case cos < -0.1:
+				vAlignment = svg.VAlignTop
+			}
+			p := point(radius+textOffset, t.position)
+			label := ticker.label(t.value)
+			m.g.Alignment(hAlignment, vAlignment).
+				Text(p.X, p.Y, svg.EncodeTextFor(label, hAlignment, m.labelFamily))
+			m.reserve(m.labelOverhang(p.X, p.Y, label, hAlignment, vAlignment))
+		} else {
+			label := ticker.label(t.value)
+			m.g.Alignment(svg.HAlignEnd, svg.VAlignCentral).
+				Text(cx-textOffset, cy-t.position, svg.EncodeTextFor(label, svg.HAlignEnd, m.labelFamily))
+			m.reserve(m.labelOverhang(cx-textOffset, cy-t.position, label, svg.HAlignEnd, svg.VAlignCentral))
+		}
+	}
+
+	if title != "" {
+		m.g.
+			FontStyle(svg.StyleNormal, svg.WeightBold)
+
+		if angular {
+			y := cy + radius + textOffset + float64(m.labelSize)*1.5
+			m.g.Alignment(svg.HAlignMiddle, svg.VAlignTop).
+				Text(cx, y, title)
+			m.reserve(m.labelOverhang(cx, y, title, svg.HAlignMiddle, svg.VAlignTop))
+		} else {
+			m.g.Alignment(svg.HAlignStart, svg.VAlignTop).
+				Text(cx+textSpacing, cy-radius+textSpacing, title)
 		}
 	}
 }
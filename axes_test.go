@@ -0,0 +1,106 @@
+package margaid
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/erkkah/margaid/xt"
+)
+
+func TestLinearMinorTicks(t *testing.T) {
+	x := xt.X(t)
+
+	m := New(100, 100, WithRange(XAxis, 0, 25))
+	major, minor := m.getMinorTicks(XAxis, []float64{0, 10, 20}, 2)
+
+	x.Equal(len(major), 3)
+	x.Equal(len(minor), 3)
+	x.Equal(minor[0], 5.0)
+	x.Equal(minor[2], 25.0)
+}
+
+func TestLogMinorTicks(t *testing.T) {
+	x := xt.X(t)
+
+	m := New(100, 100, WithProjection(YAxis, Log), WithRange(YAxis, 1, 100))
+	major, minor := m.getMinorTicks(YAxis, []float64{1, 2, 5, 10, 20, 50, 100}, 0)
+
+	x.Equal(len(major), 3)
+	x.Equal(major[1], 10.0)
+	x.Equal(len(minor), 16)
+	x.Equal(minor[0], 2.0)
+	x.Equal(minor[15], 90.0)
+}
+
+func TestSymLogMinorTicks(t *testing.T) {
+	x := xt.X(t)
+
+	m := New(100, 100, WithProjection(YAxis, SymLog), WithRange(YAxis, -200, 500))
+	ticks := []float64{-100, -10, -1, 0, 1, 10, 100}
+
+	for _, subdivisions := range []int{0, 5} {
+		major, minor := m.getMinorTicks(YAxis, ticks, subdivisions)
+
+		x.Equal(len(major), 7)
+		x.Equal(minor[0], -200.0)
+		x.Equal(fmt.Sprint(minor[len(minor)-4:]), "[200 300 400 500]")
+	}
+}
+
+type tenTicks struct{}
+
+func (tenTicks) Label(value float64) string {
+	return fmt.Sprintf("tick %v", value)
+}
+
+func (tenTicks) Start(_ Axis, _ *Series, min, _ float64, _ int) float64 {
+	return min
+}
+
+func (tenTicks) Next(previous float64) (float64, bool) {
+	return previous + 10, true
+}
+
+// axisLabels renders an x axis, 100px long, with ticks every ten
+// units from 0 to max, and returns the rendered diagram and the
+// texts of the labels drawn
+func axisLabels(max float64, options ...Option) (string, []string) {
+	m := New(200, 100, append([]Option{WithInset(50), WithRange(XAxis, 0, max)}, options...)...)
+	m.Axis(NewSeries(), XAxis, m.CustomTicker(tenTicks{}), false, "")
+	rendered := render(m)
+
+	var labels []string
+	for _, match := range regexp.MustCompile(`<text[^>]*>([^<]*)</text>`).FindAllStringSubmatch(rendered, -1) {
+		labels = append(labels, match[1])
+	}
+	return rendered, labels
+}
+
+func TestSkipLabels(t *testing.T) {
+	x := xt.X(t)
+
+	_, labels := axisLabels(100, WithLabelOverlap(XAxis, SkipLabels))
+	x.Equal(fmt.Sprint(labels), "[tick 0 tick 40 tick 80]")
+}
+
+func TestStaggerLabels(t *testing.T) {
+	x := xt.X(t)
+
+	rendered, labels := axisLabels(100, WithLabelOverlap(XAxis, StaggerLabels))
+	x.Equal(fmt.Sprint(labels), "[tick 0 tick 10 tick 40 tick 50 tick 80 tick 90]")
+	// Labels that do not fit in the first row are moved further out
+	x.Assert(strings.Contains(rendered, `x="0" y="10">tick 0<`), rendered)
+	x.Assert(strings.Contains(rendered, `x="10" y="26">tick 10<`), rendered)
+}
+
+func TestTruncateLabels(t *testing.T) {
+	x := xt.X(t)
+
+	_, labels := axisLabels(50, WithLabelOverlap(XAxis, TruncateLabels))
+	x.Equal(fmt.Sprint(labels), "[ti… ti… ti… ti… ti… ti…]")
+
+	_, labels = axisLabels(10, WithLabelOverlap(XAxis, TruncateLabels))
+	x.Equal(fmt.Sprint(labels), "[tick 0 tick 10]")
+}
@@ -5,6 +5,7 @@ import (
 	"container/list"
 	"encoding/xml"
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -34,11 +35,18 @@ func (am Attributes) Clone() Attributes {
 	return clone
 }
 
+// String formats the attributes sorted by key, to produce
+// the same output for the same set of attributes every time.
 func (am Attributes) String() string {
-	attributes := []string{}
+	keys := make([]string, 0, len(am))
+	for k := range am {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
 
-	for k, v := range am {
-		attributes = append(attributes, fmt.Sprintf("%s=%q", k, v))
+	attributes := []string{}
+	for _, k := range keys {
+		attributes = append(attributes, fmt.Sprintf("%s=%q", k, am[k]))
 	}
 
 	return strings.Join(attributes, " ")
@@ -28,6 +28,18 @@ func TestSelfClosingBracket(t *testing.T) {
 	x.Equal(b.String(), `<tag size="22"/>`)
 }
 
+func TestAttributeOrder(t *testing.T) {
+	x := xt.X(t)
+	b := New()
+	b.Add("tag", Attributes{
+		"width":  "10",
+		"height": "20",
+		"x":      "1",
+		"fill":   "none",
+	})
+	x.Equal(b.String(), `<tag fill="none" height="20" width="10" x="1"/>`)
+}
+
 func TestOpenCloseBracket(t *testing.T) {
 	x := xt.X(t)
 	b := New()
@@ -0,0 +1,85 @@
+package margaid
+
+import (
+	"fmt"
+	"math"
+)
+
+// Colormap maps a value in the range [0..1] to a color,
+// as a valid SVG color attribute string.
+type Colormap func(value float64) string
+
+type rgb struct{ r, g, b float64 }
+
+var viridisColors = []rgb{
+	{0x44, 0x01, 0x54},
+	{0x48, 0x24, 0x75},
+	{0x41, 0x44, 0x87},
+	{0x35, 0x5f, 0x8d},
+	{0x2a, 0x78, 0x8e},
+	{0x21, 0x91, 0x8c},
+	{0x22, 0xa8, 0x84},
+	{0x44, 0xbf, 0x70},
+	{0x7a, 0xd1, 0x51},
+	{0xbd, 0xdf, 0x26},
+	{0xfd, 0xe7, 0x25},
+}
+
+var magmaColors = []rgb{
+	{0x00, 0x00, 0x04},
+	{0x14, 0x0e, 0x36},
+	{0x3b, 0x0f, 0x70},
+	{0x64, 0x1a, 0x80},
+	{0x8c, 0x29, 0x81},
+	{0xb7, 0x37, 0x79},
+	{0xde, 0x49, 0x68},
+	{0xf7, 0x70, 0x5c},
+	{0xfe, 0x9f, 0x6d},
+	{0xfe, 0xcf, 0x92},
+	{0xfc, 0xfd, 0xbf},
+}
+
+var divergingColors = []rgb{
+	{0x21, 0x66, 0xac},
+	{0x67, 0xa9, 0xcf},
+	{0xd1, 0xe5, 0xf0},
+	{0xf7, 0xf7, 0xf7},
+	{0xfd, 0xdb, 0xc7},
+	{0xef, 0x8a, 0x62},
+	{0xb2, 0x18, 0x2b},
+}
+
+// Viridis is a perceptually uniform colormap from dark blue to yellow
+func Viridis(value float64) string {
+	return interpolateColors(viridisColors, value)
+}
+
+// Magma is a perceptually uniform colormap from black to light yellow
+func Magma(value float64) string {
+	return interpolateColors(magmaColors, value)
+}
+
+// Diverging is a colormap from blue through white to red,
+// for values diverging from a midpoint
+func Diverging(value float64) string {
+	return interpolateColors(divergingColors, value)
+}
+
+// interpolateColors picks a color from a list of evenly spaced colors,
+// interpolating linearly between the two closest colors.
+func interpolateColors(colors []rgb, value float64) string {
+	value = math.Max(0, math.Min(1, value))
+	position := value * float64(len(colors)-1)
+	index := int(math.Floor(position))
+	if index >= len(colors)-1 {
+		index = len(colors) - 2
+	}
+	fraction := position - float64(index)
+
+	from := colors[index]
+	to := colors[index+1]
+	mix := func(a, b float64) int {
+		return int(math.Round(a + (b-a)*fraction))
+	}
+	return fmt.Sprintf("rgb(%d, %d, %d)", mix(from.r, to.r), mix(from.g, to.g), mix(from.b, to.b))
+}
@@ -0,0 +1,115 @@
+package margaid
+
+import (
+	"math"
+	"sort"
+)
+
+// maxBins is the largest number of bins produced by the
+// width based binning functions
+const maxBins = 1000
+
+// Binning is the histogram binning function type.
+// Given sorted values, it returns the start of the first bin,
+// the bin width and the number of bins.
+type Binning func(sorted []float64) (start, width float64, count int)
+
+// BinCount divides the range of values into count bins of equal width
+func BinCount(count int) Binning {
+	return func(sorted []float64) (float64, float64, int) {
+		if count < 1 {
+			count = 1
+		}
+		min := sorted[0]
+		max := sorted[len(sorted)-1]
+		width := (max - min) / float64(count)
+		if width == 0 {
+			width = 1
+		}
+		return min, width, count
+	}
+}
+
+// BinWidth divides values into bins of the given width, starting at a
+// whole multiple of the width.
+// A width <= 0 puts all values in one bin. The width is increased if
+// needed to keep the number of bins at most 1000.
+func BinWidth(width float64) Binning {
+	return func(sorted []float64) (float64, float64, int) {
+		if width <= 0 {
+			return BinCount(1)(sorted)
+		}
+		min := sorted[0]
+		max := sorted[len(sorted)-1]
+		start := math.Floor(min/width) * width
+		width, count := limitBins(start, max, width)
+		return start, width, count
+	}
+}
+
+// limitBins returns the bin width and number of bins needed to cover
+// the range from start to max, widening the bins if there would
+// be more than maxBins of them.
+func limitBins(start, max, width float64) (float64, int) {
+	bins := math.Floor((max-start)/width) + 1
+	if bins > maxBins {
+		return (max - start) / (maxBins - 1), maxBins
+	}
+	return width, int(bins)
+}
+
+// FreedmanDiaconis selects the bin width 2*IQR/∛n, where IQR is the
+// interquartile range of the n values.
+// Falls back to Sturges' rule if the IQR is zero.
+// The width is increased if needed to keep the number of bins at most 1000.
+func FreedmanDiaconis(sorted []float64) (start, width float64, count int) {
+	n := float64(len(sorted))
+	iqr := quantile(sorted, 0.75) - quantile(sorted, 0.25)
+	if iqr == 0 {
+		return BinCount(int(math.Ceil(math.Log2(n))) + 1)(sorted)
+	}
+
+	width = 2 * iqr / math.Cbrt(n)
+	start = sorted[0]
+	width, count = limitBins(start, sorted[len(sorted)-1], width)
+	return
+}
+
+// NewHistogram creates a series counting the y values of a series in bins.
+// Each histogram value has X at the bin center, X bounds at the bin edges
+// and Y set to the number of values in the bin.
+func NewHistogram(series *Series, binning Binning, options ...SeriesOption) *Series {
+	histogram := NewSeries(options...)
+
+	var values []float64
+	iterator := series.Values()
+	for iterator.Next() {
+		values = append(values, iterator.Get().Y)
+	}
+	if len(values) == 0 {
+		return histogram
+	}
+	sort.Float64s(values)
+
+	start, width, count := binning(values)
+	bins := make([]int, count)
+
+	for _, v := range values {
+		bin := int(math.Floor((v - start) / width))
+		if bin < 0 {
+			bin = 0
+		}
+		if bin >= count {
+			bin = count - 1
+		}
+		bins[bin]++
+	}
+
+	for i, binCount := range bins {
+		low := start + float64(i)*width
+		high := low + width
+		histogram.Add(MakeValue((low+high)/2, float64(binCount)).WithXBounds(low, high))
+	}
+
+	return histogram
+}
@@ -0,0 +1,99 @@
+package margaid
+
+import (
+	"testing"
+
+	"github.com/erkkah/margaid/xt"
+)
+
+func TestHistogramBinCount(t *testing.T) {
+	x := xt.X(t)
+
+	s := NewSeries()
+	s.Zip([]float64{0, 1, 2, 3, 4}, []float64{0, 1, 1, 9, 10})
+
+	h := NewHistogram(s, BinCount(2))
+	x.Equal(h.Size(), 2)
+
+	values := h.Values()
+	x.True(values.Next())
+	v := values.Get()
+	x.Equal(v.X, 2.5)
+	x.Equal(v.Y, 3.0)
+	x.Equal(v.XBounds.Low, 0.0)
+	x.Equal(v.XBounds.High, 5.0)
+
+	x.True(values.Next())
+	x.Equal(values.Get().Y, 2.0)
+}
+
+func TestHistogramBinWidth(t *testing.T) {
+	x := xt.X(t)
+
+	s := NewSeries()
+	s.Zip([]float64{0, 1, 2, 3}, []float64{1.5, 2.5, 3, 7})
+
+	h := NewHistogram(s, BinWidth(2))
+	x.Equal(h.Size(), 4)
+	x.Equal(h.MinX(), 0.0)
+	x.Equal(h.MaxX(), 8.0)
+	x.Equal(h.MaxY(), 2.0)
+}
+
+func TestHistogramFreedmanDiaconis(t *testing.T) {
+	x := xt.X(t)
+
+	s := NewSeries()
+	for i := 0; i < 100; i++ {
+		s.Add(MakeValue(float64(i), float64(i%10)))
+	}
+
+	h := NewHistogram(s, FreedmanDiaconis)
+	total := 0.0
+	values := h.Values()
+	for values.Next() {
+		total += values.Get().Y
+	}
+	x.Equal(total, 100.0)
+	x.Assert(h.Size() > 1, "more than one bin")
+}
+
+func TestHistogramZeroBinWidth(t *testing.T) {
+	x := xt.X(t)
+
+	s := NewSeries()
+	s.Zip([]float64{0, 1, 2}, []float64{1, 2, 3})
+
+	h := NewHistogram(s, BinWidth(0))
+	x.Equal(h.Size(), 1)
+	x.Equal(h.MaxY(), 3.0)
+
+	h = NewHistogram(s, BinWidth(-1))
+	x.Equal(h.Size(), 1)
+}
+
+func TestHistogramBinWidthLimit(t *testing.T) {
+	x := xt.X(t)
+
+	s := NewSeries()
+	s.Zip([]float64{0, 1}, []float64{0, 1e9})
+
+	h := NewHistogram(s, BinWidth(1))
+	x.Equal(h.Size(), maxBins)
+	x.Equal(h.MaxY(), 1.0)
+}
+
+func TestHistogramFreedmanDiaconisLimit(t *testing.T) {
+	x := xt.X(t)
+
+	// Tightly packed values with one extreme outlier
+	s := NewSeries()
+	for i := 0; i < 100; i++ {
+		s.Add(MakeValue(float64(i), 1+float64(i)*1e-6))
+	}
+	s.Add(MakeValue(100, 1e12))
+
+	h := NewHistogram(s, FreedmanDiaconis)
+	x.Equal(h.Size(), maxBins)
+	x.Equal(h.MaxY(), 100.0)
+}
@@ -0,0 +1,186 @@
+package margaid
+
+import (
+	"math"
+	"strings"
+
+	"github.com/erkkah/margaid/metrics"
+	"github.com/erkkah/margaid/svg"
+)
+
+// insets holds the space between the plotting area
+// and each side of the diagram
+type insets struct {
+	left   float64
+	top    float64
+	right  float64
+	bottom float64
+}
+
+// max returns the largest of two insets, side by side
+func (i insets) max(other insets) insets {
+	return insets{
+		left:   math.Max(i.left, other.left),
+		top:    math.Max(i.top, other.top),
+		right:  math.Max(i.right, other.right),
+		bottom: math.Max(i.bottom, other.bottom),
+	}
+}
+
+// margin is the space kept between the diagram edges
+// and anything drawn when using automatic insets
+const margin = 2 * textSpacing
+
+// plotWidth returns the width of the plotting area
+func (m *Margaid) plotWidth() float64 {
+	return m.width - m.insets.left - m.insets.right
+}
+
+// plotHeight returns the height of the plotting area
+func (m *Margaid) plotHeight() float64 {
+	return m.height - m.insets.top - m.insets.bottom
+}
+
+// resetCanvas starts over with an empty image
+func (m *Margaid) resetCanvas() {
+	m.g = svg.New(int(m.width), int(m.height), m.background)
+	m.g.ClipRect(plotAreaClip, 0, 0, m.plotWidth(), m.plotHeight())
+	m.plots = nil
+}
+
+// record defers a drawing operation until rendering when
+// using automatic insets, see WithAutoInset.
+// Returns true if the operation was deferred, and false if
+// it should be drawn immediately.
+func (m *Margaid) record(operation func()) bool {
+	if !m.autoInset || m.replaying {
+		return false
+	}
+	m.operations = append(m.operations, operation)
+	return true
+}
+
+// layout computes the insets needed to fit everything drawn outside the
+// plotting area, and then draws all recorded operations using them.
+// The space needed is measured by drawing everything once using
+// the initial insets, recording the reserved space for each side.
+func (m *Margaid) layout() {
+	m.replaying = true
+	defer func() {
+		m.replaying = false
+	}()
+
+	m.insets = m.initialInsets
+	m.reserved = insets{}
+	m.labelSpace = insets{}
+	m.resetCanvas()
+	for _, operation := range m.operations {
+		operation()
+	}
+
+	m.insets = insets{
+		left:   m.reserved.left + margin,
+		top:    m.reserved.top + margin,
+		right:  m.reserved.right + margin,
+		bottom: m.reserved.bottom + margin,
+	}
+	m.resetCanvas()
+	for _, operation := range m.operations {
+		operation()
+	}
+}
+
+// reserve records space needed outside the plotting area, measured
+// outwards from each edge of the plotting area
+func (m *Margaid) reserve(space insets) {
+	m.reserved = m.reserved.max(space)
+}
+
+// overhang returns how far a box in diagram coordinates sticks out
+// of the plotting area on each side
+func (m *Margaid) overhang(left, top, right, bottom float64) insets {
+	return insets{
+		left:   m.insets.left - left,
+		top:    m.insets.top - top,
+		right:  right - (m.width - m.insets.right),
+		bottom: bottom - (m.height - m.insets.bottom),
+	}
+}
+
+// labelOverhang returns how far a label drawn at x, y in diagram coordinates
+// using the label font and the given alignment sticks out of the plotting
+// area on each side.
+// Multi line text is expected to flow downwards, as laid out by svg.EncodeText.
+func (m *Margaid) labelOverhang(x, y float64, text string,
+	hAlignment svg.HAlignment, vAlignment svg.VAlignment) insets {
+	return m.overhang(textBox(x, y, text, m.labelFamily, m.labelSize, hAlignment, vAlignment))
+}
+
+// textBox estimates the bounding box of text drawn at x, y
+func textBox(x, y float64, text string, family string, size int,
+	hAlignment svg.HAlignment, vAlignment svg.VAlignment) (left, top, right, bottom float64) {
+
+	width, height := textSize(text, family, size)
+	lineHeight := float64(size)
+
+	switch hAlignment {
+	case svg.HAlignStart:
+		left = x
+	case svg.HAlignMiddle:
+		left = x - width/2
+	case svg.HAlignEnd:
+		left = x - width
+	}
+
+	switch vAlignment {
+	case svg.VAlignTop:
+		top = y
+	case svg.VAlignCentral:
+		top = y - lineHeight/2
+	case svg.VAlignBottom:
+		top = y - 0.8*lineHeight
+	}
+
+	return left, top, left + width, top + height
+}
+
+// rotatedBox returns the bounding box of a box rotated
+// counterclockwise by angle degrees around x, y
+func rotatedBox(left, top, right, bottom, x, y, angle float64) (float64, float64, float64, float64) {
+	if angle == 0 {
+		return left, top, right, bottom
+	}
+
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+
+	for _, corner := range [][2]float64{{left, top}, {right, top}, {right, bottom}, {left, bottom}} {
+		dx := corner[0] - x
+		dy := corner[1] - y
+		// Diagram y coordinates grow downwards
+		rx := x + dx*cos + dy*sin
+		ry := y - dx*sin + dy*cos
+		minX, maxX = math.Min(minX, rx), math.Max(maxX, rx)
+		minY, maxY = math.Min(minY, ry), math.Max(maxY, ry)
+	}
+	return minX, minY, maxX, maxY
+}
+
+// truncateText shortens each line of text to at most length characters,
+// marking shortened lines with an ellipsis
+func truncateText(text string, length int) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if runes := []rune(line); len(runes) > length {
+			lines[i] = string(runes[:length]) + "…"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// textSize estimates the width and height in pixels of possibly
+// multi line text, using a font of the given family and pixel size.
+func textSize(text string, family string, size int) (width, height float64) {
+	return metrics.ForFamily(family).Size(text, float64(size))
+}
@@ -0,0 +1,58 @@
+package margaid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/erkkah/margaid/xt"
+)
+
+func TestTextSize(t *testing.T) {
+	x := xt.X(t)
+
+	narrow, _ := textSize("iii", "sans-serif", 10)
+	wide, _ := textSize("MMM", "sans-serif", 10)
+	x.Assert(narrow < wide)
+
+	width, height := textSize("MMM\nii", "sans-serif", 10)
+	x.Equal(width, wide)
+	x.Equal(height, 20.0)
+}
+
+func TestAutoInset(t *testing.T) {
+	x := xt.X(t)
+
+	s := NewSeries()
+	s.Add(MakeValue(0, 0), MakeValue(1, 1000000))
+
+	wide := New(400, 300, WithAutoInset(), WithAutorange(YAxis, s))
+	wide.Axis(s, YAxis, wide.ValueTicker('f', 0, 10), false, "")
+	wide.layout()
+
+	narrow := New(400, 300, WithAutoInset(), WithRange(YAxis, 0, 5))
+	narrow.Axis(s, YAxis, narrow.ValueTicker('f', 0, 10), false, "")
+	narrow.layout()
+
+	x.Assert(wide.insets.left > narrow.insets.left)
+	x.Assert(narrow.insets.left > margin)
+	x.Equal(wide.insets.right, float64(margin))
+}
+
+func TestRotatedBox(t *testing.T) {
+	x := xt.X(t)
+
+	// A 10 x 2 box ending at the pivot, turned upright
+	left, top, right, bottom := rotatedBox(-10, -1, 0, 1, 0, 0, 90)
+	x.Assert(math.Abs(left+1) < 1e-9)
+	x.Assert(math.Abs(right-1) < 1e-9)
+	x.Assert(math.Abs(top) < 1e-9)
+	x.Assert(math.Abs(bottom-10) < 1e-9)
+}
+
+func TestTruncateText(t *testing.T) {
+	x := xt.X(t)
+
+	x.Equal(truncateText("September", 3), "Sep…")
+	x.Equal(truncateText("May", 3), "May")
+	x.Equal(truncateText("12:00\nSeptember 4", 5), "12:00\nSepte…")
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"strconv"
+	"time"
 
 	"github.com/erkkah/margaid/brackets"
+	"github.com/erkkah/margaid/metrics"
 	"github.com/erkkah/margaid/svg"
 )
 
@@ -15,13 +18,31 @@ type Margaid struct {
 
 	width   float64
 	height  float64
-	inset   float64
+	insets  insets
 	padding float64 // padding [0..1]
 
+	autoInset     bool
+	initialInsets insets   // insets used for measuring, see layout
+	operations    []func() // deferred drawing, see record
+	replaying     bool
+	reserved      insets // space needed outside the plotting area
+	labelSpace    insets // space needed for axis labels
+
 	projections map[Axis]Projection
 	ranges      map[Axis]minmax
+	thresholds  map[Axis]float64
+	reversed    map[Axis]bool
+
+	labels          map[Axis]labelStyle
+	minorTicks      map[Axis]minorTicks
+	gridStyles      map[Axis]GridStyle
+	minorGridStyles map[Axis]GridStyle
+
+	polar    bool
+	clip     bool
+	location *time.Location
 
-	plots       []string
+	plots       []plot
 	background  string
 	colorScheme int
 
@@ -37,6 +58,42 @@ const (
 	tickDistance   = 55
 	tickSize       = 6
 	textSpacing    = 4
+	markerSize     = 4
+
+	defaultBubbleSize = 20
+
+	plotAreaClip = "plot-area"
+)
+
+// plot holds the legend information for one drawn plot
+type plot struct {
+	name   string
+	marker string // legend marker, or "" for a plain box
+	color  string // legend color, or "" for the plot color
+}
+
+// labelStyle holds the tick label settings of an axis
+type labelStyle struct {
+	rotation float64
+	overlap  LabelOverlap
+}
+
+// minorTicks holds the minor tick settings of an axis
+type minorTicks struct {
+	subdivisions int
+	grid         bool
+}
+
+// GridStyle describes the look of axis grid lines
+type GridStyle struct {
+	Color string
+	Width float64 // stroke width in pixels
+	Dash  string  // stroke dash pattern, see svg.Dash
+}
+
+var (
+	defaultGridStyle      = GridStyle{Color: "gray", Width: 0.5}
+	defaultMinorGridStyle = GridStyle{Color: "lightgray", Width: 0.5}
 )
 
 // minmax is the range [min, max] of a chart axis
@@ -50,10 +107,13 @@ func New(width, height int, options ...Option) *Margaid {
 	defaultRange := minmax{0, 100}
 
 	self := &Margaid{
-		inset:   defaultInset,
+		insets:  insets{defaultInset, defaultInset, defaultInset, defaultInset},
 		width:   float64(width),
 		height:  float64(height),
 		padding: defaultPadding,
+		clip:    true,
+
+		location: time.Local,
 
 		projections: map[Axis]Projection{
 			X1Axis: Lin,
@@ -69,6 +129,32 @@ func New(width, height int, options ...Option) *Margaid {
 			Y2Axis: defaultRange,
 		},
 
+		thresholds: map[Axis]float64{
+			X1Axis: 1,
+			X2Axis: 1,
+			Y1Axis: 1,
+			Y2Axis: 1,
+		},
+
+		reversed: map[Axis]bool{},
+
+		labels:     map[Axis]labelStyle{},
+		minorTicks: map[Axis]minorTicks{},
+
+		gridStyles: map[Axis]GridStyle{
+			X1Axis: defaultGridStyle,
+			X2Axis: defaultGridStyle,
+			Y1Axis: defaultGridStyle,
+			Y2Axis: defaultGridStyle,
+		},
+
+		minorGridStyles: map[Axis]GridStyle{
+			X1Axis: defaultMinorGridStyle,
+			X2Axis: defaultMinorGridStyle,
+			Y1Axis: defaultMinorGridStyle,
+			Y2Axis: defaultMinorGridStyle,
+		},
+
 		background:  "transparent",
 		colorScheme: 198,
 		titleFamily: "sans-serif",
@@ -81,7 +167,8 @@ func New(width, height int, options ...Option) *Margaid {
 		o(self)
 	}
 
-	self.g = svg.New(width, height, self.background)
+	self.initialInsets = self.insets
+	self.resetCanvas()
 
 	return self
 }
@@ -91,10 +178,13 @@ func New(width, height int, options ...Option) *Margaid {
 // Projection is the type for the projection constants
 type Projection int
 
-// Projection constants
+// Projection constants.
+// SymLog is a symmetric log projection, that handles values <= 0
+// by projecting values close to zero linearly, see WithSymLogThreshold.
 const (
 	Lin Projection = iota + 'p'
 	Log
+	SymLog
 )
 
 // WithProjection sets the projection for a given axis
@@ -104,6 +194,95 @@ func WithProjection(axis Axis, proj Projection) Option {
 	}
 }
 
+// WithSymLogThreshold sets the size of the linear region around zero
+// for an axis using the SymLog projection. The default threshold is 1.
+func WithSymLogThreshold(axis Axis, threshold float64) Option {
+	return func(m *Margaid) {
+		m.thresholds[axis] = threshold
+	}
+}
+
+// WithReversed reverses the direction of an axis, projecting
+// the range maximum at the start of the axis.
+func WithReversed(axis Axis) Option {
+	return func(m *Margaid) {
+		m.reversed[axis] = true
+	}
+}
+
+// LabelOverlap decides how to handle tick labels that would overlap
+type LabelOverlap int
+
+// LabelOverlap constants
+const (
+	// SkipLabels leaves out labels that would overlap, the default
+	SkipLabels LabelOverlap = iota + 'o'
+	// StaggerLabels places labels that would overlap in a second row
+	StaggerLabels
+	// TruncateLabels shortens labels to fit the tick distance
+	TruncateLabels
+)
+
+// WithLabelRotation rotates the tick labels of an axis counterclockwise
+// by the given angle in degrees, typically 45 or 90 for long x axis labels.
+// Rotated labels end at their tick mark.
+func WithLabelRotation(axis Axis, angle float64) Option {
+	return func(m *Margaid) {
+		style := m.labels[axis]
+		style.rotation = angle
+		m.labels[axis] = style
+	}
+}
+
+// WithLabelOverlap sets how to handle overlapping tick labels of an axis.
+// Labels that still overlap after staggering or truncating are left out.
+func WithLabelOverlap(axis Axis, overlap LabelOverlap) Option {
+	return func(m *Margaid) {
+		style := m.labels[axis]
+		style.overlap = overlap
+		m.labels[axis] = style
+	}
+}
+
+// WithMinorTicks adds shorter, unlabeled tick marks between the major
+// tick marks of an axis, optionally with grid lines.
+// On linear axes, the space between major ticks is split into the given
+// number of subdivisions. On log axes, major ticks are placed at whole
+// powers of ten, with minor ticks at 2..9 times each power.
+// Minor ticks are not drawn in polar charts.
+func WithMinorTicks(axis Axis, subdivisions int, grid bool) Option {
+	return func(m *Margaid) {
+		m.minorTicks[axis] = minorTicks{subdivisions, grid}
+	}
+}
+
+// WithGridStyle sets the style of the major grid lines of an axis
+func WithGridStyle(axis Axis, style GridStyle) Option {
+	return func(m *Margaid) {
+		m.gridStyles[axis] = style
+	}
+}
+
+// WithMinorGridStyle sets the style of the minor grid lines of an axis
+func WithMinorGridStyle(axis Axis, style GridStyle) Option {
+	return func(m *Margaid) {
+		m.minorGridStyles[axis] = style
+	}
+}
+
+// WithPolar switches the chart to polar coordinates.
+// The x axis runs clockwise around a circle starting at the top,
+// covering the x axis range in one full turn, and the y axis runs
+// from the center of the circle outwards.
+// Line, smooth, scatter, bubble and radar plots can be drawn in polar
+// coordinates, without error bars or bands.
+// Other plots and annotations report an error.
+func WithPolar() Option {
+	return func(m *Margaid) {
+		m.polar = true
+	}
+}
+
 // WithRange sets a fixed plotting range for a given axis
 func WithRange(axis Axis, min, max float64) Option {
 	return func(m *Margaid) {
@@ -149,11 +328,54 @@ func WithAutorange(axis Axis, series ...*Series) Option {
 	}
 }
 
+// WithStackedAutorange sets range for an axis from the values of one or more
+// series stacked along that axis, as drawn by stacked bars and areas.
+// Use WithAutorange for the category axis.
+func WithStackedAutorange(axis Axis, series ...*Series) Option {
+	return func(m *Margaid) {
+		horizontal := axis == X1Axis || axis == X2Axis
+		lower, upper := stack(series, horizontal)
+		stacked := NewSeries()
+		for i := range series {
+			stacked.Add(lower[i]...)
+			stacked.Add(upper[i]...)
+		}
+		WithAutorange(axis, stacked)(m)
+	}
+}
+
+// WithClipping enables or disables clipping of data plots to the
+// plot area. Clipping is enabled by default.
+func WithClipping(clip bool) Option {
+	return func(m *Margaid) {
+		m.clip = clip
+	}
+}
+
+// WithLocation sets the location used for presenting time values,
+// such as the labels of time tickers. The default location is time.Local.
+func WithLocation(location *time.Location) Option {
+	return func(m *Margaid) {
+		m.location = location
+	}
+}
+
 // WithInset sets the distance between the chart boundaries and the
 // charting area.
 func WithInset(inset float64) Option {
 	return func(m *Margaid) {
-		m.inset = inset
+		m.insets = insets{inset, inset, inset, inset}
+	}
+}
+
+// WithAutoInset computes the inset of each side from the space needed
+// by axis labels, titles and legends drawn outside the plotting area.
+// All drawing is deferred until Render, where it is first measured and
+// then drawn using the computed insets. Any changes to the plotted series
+// before rendering will show in the rendered image.
+func WithAutoInset() Option {
+	return func(m *Margaid) {
+		m.autoInset = true
 	}
 }
 
@@ -202,14 +424,20 @@ func WithLabelFont(family string, size int) Option {
 
 // Title draws a title top center
 func (m *Margaid) Title(title string) {
-	encoded := svg.EncodeText(title, svg.HAlignMiddle)
+	if m.record(func() { m.Title(title) }) {
+		return
+	}
+
+	encoded := svg.EncodeTextFor(title, svg.HAlignMiddle, m.titleFamily)
+	_, height := textSize(title, m.titleFamily, m.titleSize)
+	m.reserve(insets{top: height + textSpacing})
 	m.g.
 		Font(m.titleFamily, fmt.Sprintf("%dpx", m.titleSize)).
 		FontStyle(svg.StyleNormal, svg.WeightBold).
 		Alignment(svg.HAlignMiddle, svg.VAlignCentral).
 		Transform().
 		Fill("black").
-		Text(m.width/2, m.inset/2, encoded)
+		Text(m.width/2, m.insets.top/2, encoded)
 }
 
 // LegendPosition decides where to draw the legend
@@ -223,21 +451,31 @@ const (
 )
 
 // Legend draws a legend for named plots. If position is set to BottomLeft, it
-// will grow the plot size to accommodate the number of legends displayed.
+// will grow the plot size to accommodate the number of legends displayed,
+// unless using automatic insets, see WithAutoInset.
 func (m *Margaid) Legend(position LegendPosition) {
+	if m.record(func() { m.Legend(position) }) {
+		return
+	}
+
 	type namedPlot struct {
-		name  string
-		color string
+		name   string
+		color  string
+		marker string
 	}
 
 	var plots []namedPlot
 
-	for i, label := range m.plots {
-		if label != "" {
-			color := m.getPlotColor(i)
+	for i, p := range m.plots {
+		if p.name != "" {
+			color := p.color
+			if color == "" {
+				color = m.getPlotColor(i)
+			}
 			plots = append(plots, namedPlot{
-				name:  label,
-				color: color,
+				name:   p.name,
+				color:  color,
+				marker: p.marker,
 			})
 		}
 	}
@@ -250,14 +488,14 @@ func (m *Margaid) Legend(position LegendPosition) {
 
 	switch position {
 	case RightTop:
-		listStartX = m.width - m.inset + boxSize + textSpacing
-		listStartY = m.inset + 0.5*boxSize
+		listStartX = m.width - m.insets.right + boxSize + textSpacing
+		listStartY = m.insets.top + 0.5*boxSize
 	case RightBottom:
-		listStartX = m.width - m.inset + boxSize + textSpacing
-		listStartY = m.height - m.inset - lineHeight*float64(len(plots))
+		listStartX = m.width - m.insets.right + boxSize + textSpacing
+		listStartY = m.height - m.insets.bottom - lineHeight*float64(len(plots))
 	case BottomLeft:
-		listStartX = m.inset + 0.5*boxSize
-		listStartY = m.height - m.inset + lineHeight + boxSize + tickSize
+		listStartX = m.insets.left + 0.5*boxSize
+		listStartY = m.height - m.insets.bottom + math.Max(lineHeight+tickSize, m.labelSpace.bottom) + boxSize
 	}
 
 	style := func(color string) {
@@ -274,17 +512,120 @@ func (m *Margaid) Legend(position LegendPosition) {
 		yPos := listStartY + floatIndex*lineHeight
 		xPos := listStartX
 		style(plot.color)
-		m.g.Rect(xPos, yPos, boxSize, boxSize)
+		if plot.marker != "" {
+			m.drawMarker(plot.marker, plot.color, xPos+boxSize/2, yPos+boxSize/2, boxSize/2)
+		} else {
+			m.g.Rect(xPos, yPos, boxSize, boxSize)
+		}
 		style("black")
 		m.g.Text(xPos+boxSize+textSpacing, yPos, brackets.XMLEscape(plot.name))
+
+		width := boxSize + textSpacing + metrics.ForFamily(m.labelFamily).Width(plot.name, float64(m.labelSize))
+		m.reserve(m.overhang(xPos, yPos, xPos+width, yPos+lineHeight))
 	}
 
-	if position == BottomLeft {
+	if position == BottomLeft && !m.autoInset {
 		newHeight := int(m.height + lineHeight*float64(len(plots)))
 		m.g.SetSize(int(m.width), newHeight)
 	}
 }
 
+// ColorBar draws a color scale for the z range of a series to the right
+// of the plotting area, as a legend for heatmaps.
+// The colormap is selected by UsingColormap.
+func (m *Margaid) ColorBar(series *Series, using ...Using) {
+	if m.record(func() { m.ColorBar(series, using...) }) {
+		return
+	}
+
+	options := getPlotOptions(using)
+
+	const slices = 32
+	const labels = 5
+
+	barWidth := float64(m.labelSize)
+	barHeight := m.plotHeight()
+	sliceHeight := barHeight / slices
+	xPos := m.width - m.insets.right + 2*textSpacing
+
+	m.g.
+		Transform().
+		StrokeWidth("0.5px")
+
+	for i := 0; i < slices; i++ {
+		level := (float64(i) + 0.5) / slices
+		yPos := m.height - m.insets.bottom - float64(i+1)*sliceHeight
+		m.g.
+			Color(options.colormap(level)).
+			Rect(xPos, yPos, barWidth, sliceHeight)
+	}
+	m.reserve(m.overhang(xPos, m.insets.top, xPos+barWidth, m.height-m.insets.bottom))
+
+	m.g.
+		Font(m.labelFamily, fmt.Sprintf("%dpx", m.labelSize)).
+		FontStyle(svg.StyleNormal, svg.WeightNormal).
+		Alignment(svg.HAlignStart, svg.VAlignCentral).
+		Fill("black").
+		Stroke("none")
+
+	minZ := series.MinZ()
+	zRange := series.MaxZ() - minZ
+	for i := 0; i < labels; i++ {
+		fraction := float64(i) / (labels - 1)
+		label := strconv.FormatFloat(minZ+fraction*zRange, 'g', 4, 64)
+		x := xPos + barWidth + textSpacing
+		y := m.height - m.insets.bottom - fraction*barHeight
+		m.g.Text(x, y, label)
+		m.reserve(m.labelOverhang(x, y, label, svg.HAlignStart, svg.VAlignCentral))
+	}
+}
+
+// SizeLegend draws nested circles showing the bubble sizes of a series
+// to the right of the plotting area, as a legend for bubble plots.
+// The bubble size is set by UsingBubbleSize.
+func (m *Margaid) SizeLegend(series *Series, using ...Using) {
+	if m.record(func() { m.SizeLegend(series, using...) }) {
+		return
+	}
+
+	options := getPlotOptions(using)
+
+	maxSize := series.MaxZ()
+	if maxSize <= 0 {
+		return
+	}
+
+	radius := options.bubbleSize
+	xPos := m.width - m.insets.right + 2*textSpacing + radius
+	bottom := m.height - m.insets.bottom
+	sizes := []float64{maxSize, maxSize / 4, maxSize / 16}
+
+	m.g.
+		Transform().
+		StrokeWidth("1px").
+		Fill("none").
+		Stroke("gray")
+
+	for _, size := range sizes {
+		r := bubbleRadius(size, maxSize, radius)
+		m.g.Circle(xPos, bottom-r, r)
+	}
+
+	m.g.
+		Font(m.labelFamily, fmt.Sprintf("%dpx", m.labelSize)).
+		FontStyle(svg.StyleNormal, svg.WeightNormal).
+		Alignment(svg.HAlignStart, svg.VAlignCentral).
+		Fill("black").
+		Stroke("none")
+
+	for _, size := range sizes {
+		r := bubbleRadius(size, maxSize, radius)
+		label := strconv.FormatFloat(size, 'g', 4, 64)
+		m.g.Text(xPos+radius+textSpacing, bottom-2*r, label)
+		m.reserve(m.labelOverhang(xPos+radius+textSpacing, bottom-2*r, label, svg.HAlignStart, svg.VAlignCentral))
+	}
+}
+
 func (m *Margaid) error(message string) {
 	m.g.
 		Font(m.titleFamily, fmt.Sprintf("%dpx", m.titleSize)).
@@ -292,18 +633,31 @@ func (m *Margaid) error(message string) {
 		Alignment(svg.HAlignStart, svg.VAlignCentral).
 		Transform().
 		StrokeWidth("0").Fill("red").
-		Text(5, m.inset/2, brackets.XMLEscape(message))
+		Text(5, m.insets.top/2, brackets.XMLEscape(message))
 }
 
-// Frame draws a frame around the chart area
+// Frame draws a frame around the chart area.
+// In polar coordinates, the frame is a circle.
 func (m *Margaid) Frame() {
+	if m.record(func() { m.Frame() }) {
+		return
+	}
+
 	m.g.Transform()
 	m.g.Fill("none").Stroke("black").StrokeWidth("2px")
-	m.g.Rect(m.inset, m.inset, m.width-m.inset*2, m.height-m.inset*2)
+	if m.polar {
+		cx, cy, radius := m.polarCircle()
+		m.g.Circle(m.insets.left+cx, m.height-m.insets.bottom-cy, radius)
+		return
+	}
+	m.g.Rect(m.insets.left, m.insets.top, m.plotWidth(), m.plotHeight())
 }
 
 // Render renders the graph to the given destination.
 func (m *Margaid) Render(writer io.Writer) error {
+	if m.autoInset {
+		m.layout()
+	}
 	rendered := m.g.Render()
 	_, err := writer.Write([]byte(rendered))
 	return err
@@ -322,9 +676,9 @@ func (m *Margaid) project(value float64, axis Axis) (float64, error) {
 	var axisLength float64
 	switch {
 	case axis == X1Axis || axis == X2Axis:
-		axisLength = m.width - 2*m.inset
+		axisLength = m.plotWidth()
 	case axis == Y1Axis || axis == Y2Axis:
-		axisLength = m.height - 2*m.inset
+		axisLength = m.plotHeight()
 	}
 
 	axisPadding := m.padding * axisLength
@@ -344,23 +698,149 @@ func (m *Margaid) project(value float64, axis Axis) (float64, error) {
 		max = math.Log10(max)
 	}
 
+	if projection == SymLog {
+		threshold := m.thresholds[axis]
+		projected = symLog(value, threshold)
+		min = symLog(min, threshold)
+		max = symLog(max, threshold)
+	}
+
+	if m.reversed[axis] {
+		min, max = max, min
+	}
+
 	projected = axisPadding + (axisLength-2*axisPadding)*(projected-min)/(max-min)
 	return projected, nil
 }
 
+// symLog is a log like function that is defined for all values.
+// It is linear within threshold from zero, and logarithmic outside,
+// with one unit per decade.
+func symLog(value, threshold float64) float64 {
+	magnitude := math.Abs(value) / threshold
+	if magnitude <= 1 {
+		return value / threshold
+	}
+	return math.Copysign(1+math.Log10(magnitude), value)
+}
+
 func (m *Margaid) getProjectedValues(series *Series, xAxis, yAxis Axis) (points []struct{ X, Y float64 }, err error) {
+	var values []Value
+	iterator := series.Values()
+	for iterator.Next() {
+		values = append(values, iterator.Get())
+	}
+	return m.projectValues(values, xAxis, yAxis)
+}
+
+func (m *Margaid) projectValues(values []Value, xAxis, yAxis Axis) (points []struct{ X, Y float64 }, err error) {
+	for _, v := range values {
+		var p struct{ X, Y float64 }
+		p.X, err = m.project(v.X, xAxis)
+		if err != nil {
+			return
+		}
+		p.Y, err = m.project(v.Y, yAxis)
+		if err != nil {
+			return
+		}
+		if m.polar {
+			p.X, p.Y = m.polarPoint(p.X, p.Y)
+		}
+		points = append(points, p)
+	}
+	return
+}
+
+// plotClip returns the clip path id for data plots,
+// or "" if clipping is disabled.
+func (m *Margaid) plotClip() string {
+	if m.clip {
+		return plotAreaClip
+	}
+	return ""
+}
+
+// polarCircle returns the center and radius of the polar plotting
+// circle, in the same coordinates as the projected values.
+func (m *Margaid) polarCircle() (cx, cy, radius float64) {
+	plotWidth := m.plotWidth()
+	plotHeight := m.plotHeight()
+	return plotWidth / 2, plotHeight / 2, math.Min(plotWidth, plotHeight) / 2
+}
+
+// notPolar reports an error and returns true when drawing in polar
+// coordinates, for plots that can only be drawn in cartesian coordinates.
+func (m *Margaid) notPolar(plot string) bool {
+	if m.polar {
+		m.error(fmt.Sprintf("%s do not support polar coordinates", plot))
+		return true
+	}
+	return false
+}
+
+// polarPoint converts projected x and y values to a point on the polar
+// plotting circle, with x as the angle and y as the radius.
+func (m *Margaid) polarPoint(x, y float64) (float64, float64) {
+	plotWidth := m.plotWidth()
+	plotHeight := m.plotHeight()
+	cx, cy, radius := m.polarCircle()
+
+	angle := 2 * math.Pi * x / plotWidth
+	distance := radius * y / plotHeight
+	return cx + distance*math.Sin(angle), cy + distance*math.Cos(angle)
+}
+
+// getProjectedBounds projects the lower and upper bounds of each series value.
+// Values without bounds are projected as is.
+func (m *Margaid) getProjectedBounds(series *Series, xAxis, yAxis Axis) (lower, upper []struct{ X, Y float64 }, err error) {
+	var lowValues, highValues []Value
 	values := series.Values()
 	for values.Next() {
 		v := values.Get()
-		v.X, err = m.project(v.X, xAxis)
-		if err != nil {
-			return
+		low := MakeValue(v.X, v.Y)
+		high := low
+		if v.XBounds != nil {
+			low.X = v.XBounds.Low
+			high.X = v.XBounds.High
+		}
+		if v.YBounds != nil {
+			low.Y = v.YBounds.Low
+			high.Y = v.YBounds.High
+		}
+		lowValues = append(lowValues, low)
+		highValues = append(highValues, high)
+	}
+
+	lower, err = m.projectValues(lowValues, xAxis, yAxis)
+	if err != nil {
+		return
+	}
+	upper, err = m.projectValues(highValues, xAxis, yAxis)
+	return
+}
+
+// projectStackBases projects the lower values of a stacked series onto an axis.
+// The lowest series of a stack starts at the baseline value instead.
+// On log axes, where zero can not be drawn, stacks start at the axis minimum.
+func (m *Margaid) projectStackBases(lower []Value, axis Axis, baseline float64, lowest bool) (bases []float64, err error) {
+	for _, v := range lower {
+		value := v.Y
+		if axis == X1Axis || axis == X2Axis {
+			value = v.X
+		}
+		if lowest {
+			value = baseline
 		}
-		v.Y, err = m.project(v.Y, yAxis)
+		if value <= 0 && m.projections[axis] == Log {
+			value = m.ranges[axis].min
+		}
+		var base float64
+		base, err = m.project(value, axis)
 		if err != nil {
 			return
 		}
-		points = append(points, v)
+		bases = append(bases, base)
 	}
 	return
 }
@@ -368,15 +848,34 @@ func (m *Margaid) getProjectedValues(series *Series, xAxis, yAxis Axis) (points
 // addPlot adds a named plot and returns its ID
 func (m *Margaid) addPlot(name string) int {
 	id := len(m.plots)
-	m.plots = append(m.plots, name)
+	m.plots = append(m.plots, plot{name: name})
+	return id
+}
+
+// addMarkerPlot adds a named plot shown with a marker in the legend
+// and returns its ID
+func (m *Margaid) addMarkerPlot(name string, marker string) int {
+	id := m.addPlot(name)
+	m.plots[id].marker = marker
 	return id
 }
 
 // getPlotColor picks hues and saturations around the color wheel at prime indices.
 // Kind of works for a quick selection of plotting colors.
 func (m *Margaid) getPlotColor(id int) string {
-	color := 211*id + m.colorScheme
-	hue := color % 360
-	saturation := 47 + (id*41)%53
+	hue, saturation := m.getPlotHueSaturation(id)
 	return fmt.Sprintf("hsl(%d, %d%%, 65%%)", hue, saturation)
 }
+
+// getPlotFillColor returns a translucent version of the plot color
+func (m *Margaid) getPlotFillColor(id int) string {
+	hue, saturation := m.getPlotHueSaturation(id)
+	return fmt.Sprintf("hsla(%d, %d%%, 65%%, 0.4)", hue, saturation)
+}
+
+func (m *Margaid) getPlotHueSaturation(id int) (hue, saturation int) {
+	color := 211*id + m.colorScheme
+	hue = color % 360
+	saturation = 47 + (id*41)%53
+	return
+}
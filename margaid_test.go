@@ -0,0 +1,115 @@
+package margaid
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/erkkah/margaid/xt"
+)
+
+func TestReversedProjection(t *testing.T) {
+	x := xt.X(t)
+
+	m := New(100, 100, WithInset(0), WithReversed(YAxis), WithRange(YAxis, 0, 10))
+
+	top, err := m.project(10, YAxis)
+	x.Nil(err)
+	x.Equal(top, 0.0)
+
+	bottom, err := m.project(0, YAxis)
+	x.Nil(err)
+	x.Equal(bottom, 100.0)
+}
+
+func TestSymLogProjection(t *testing.T) {
+	x := xt.X(t)
+
+	m := New(100, 100, WithInset(0), WithProjection(XAxis, SymLog), WithRange(XAxis, -1000, 1000))
+
+	zero, err := m.project(0, XAxis)
+	x.Nil(err)
+	x.Equal(zero, 50.0)
+
+	// Linear within the threshold
+	low, _ := m.project(-0.5, XAxis)
+	high, _ := m.project(0.5, XAxis)
+	x.Equal(low, 43.75)
+	x.Equal(high, 56.25)
+
+	// Logarithmic outside, with the linear region as wide as a decade
+	low, _ = m.project(-10, XAxis)
+	high, _ = m.project(10, XAxis)
+	x.Equal(low, 25.0)
+	x.Equal(high, 75.0)
+}
+
+func TestPolarPoint(t *testing.T) {
+	x := xt.X(t)
+
+	m := New(200, 100, WithInset(0), WithPolar())
+
+	cx, cy, radius := m.polarCircle()
+	x.Equal(cx, 100.0)
+	x.Equal(cy, 50.0)
+	x.Equal(radius, 50.0)
+
+	near := func(a, b float64) bool {
+		return math.Abs(a-b) < 1e-9
+	}
+
+	for _, c := range []struct{ x, y, px, py float64 }{
+		{0, 0, 100, 50},
+		{0, 100, 100, 100},
+		{50, 100, 150, 50},
+		{100, 50, 100, 25},
+		{150, 100, 50, 50},
+	} {
+		px, py := m.polarPoint(c.x, c.y)
+		x.Assert(near(px, c.px) && near(py, c.py), c, px, py)
+	}
+}
+
+func TestPolarUnsupported(t *testing.T) {
+	x := xt.X(t)
+
+	series := NewSeries()
+	series.Add(MakeValue(1, 2), MakeValue(2, 3))
+
+	m := New(100, 100, WithPolar())
+	m.Bar([]*Series{series})
+	m.Region(XAxis, 1, 2, "red")
+	rendered := render(m)
+	x.Assert(strings.Contains(rendered, "bar charts do not support polar coordinates"))
+	x.Assert(strings.Contains(rendered, "regions do not support polar coordinates"))
+
+	m = New(100, 100, WithPolar())
+	m.Step(series)
+	m.Line(series, UsingErrorBars())
+	m.Smooth(series, UsingBand())
+	rendered = render(m)
+	x.Assert(strings.Contains(rendered, "step plots do not support polar coordinates"))
+	x.Equal(strings.Count(rendered, "error bars and bands do not support polar coordinates"), 2)
+
+	m = New(100, 100, WithPolar())
+	m.Line(series)
+	m.Smooth(series)
+	x.False(strings.Contains(render(m), "polar coordinates"))
+}
+
+func TestClipping(t *testing.T) {
+	x := xt.X(t)
+
+	series := NewSeries()
+	series.Add(MakeValue(0, 0), MakeValue(20, 20))
+
+	m := New(100, 100, WithRange(XAxis, 0, 10), WithRange(YAxis, 0, 10))
+	m.Line(series)
+	m.Frame()
+	rendered := render(m)
+	x.Equal(strings.Count(rendered, `clip-path="url(#plot-area)"`), 1, rendered)
+
+	m = New(100, 100, WithRange(XAxis, 0, 10), WithRange(YAxis, 0, 10), WithClipping(false))
+	m.Line(series)
+	x.False(strings.Contains(render(m), "clip-path="))
+}
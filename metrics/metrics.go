@@ -0,0 +1,133 @@
+// Package metrics estimates the rendered size of text, using the
+// advance widths of common fonts.
+//
+// The widths are those of the standard Helvetica, Times and Courier fonts,
+// which closely match the widths of the fonts typically used for the generic
+// sans-serif, serif and monospace font families.
+package metrics
+
+import (
+	"strings"
+)
+
+// Face holds the advance widths of a font face
+type Face struct {
+	// widths of the printable ASCII characters, in 1/1000 em
+	widths [95]uint16
+	// width of all other characters, in 1/1000 em
+	fallback uint16
+}
+
+// Helvetica metrics, for sans-serif fonts
+var Helvetica = &Face{
+	widths: [95]uint16{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space - /
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, // 0 - 9
+		278, 278, 584, 584, 584, 556, 1015, // : - @
+		667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, // A - M
+		722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, // N - Z
+		278, 278, 278, 469, 556, 333, // [ - `
+		556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, // a - m
+		556, 556, 556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, // n - z
+		334, 260, 334, 584, // { - ~
+	},
+	fallback: 556,
+}
+
+// Times metrics, for serif fonts
+var Times = &Face{
+	widths: [95]uint16{
+		250, 333, 408, 500, 500, 833, 778, 180, 333, 333, 500, 564, 250, 333, 250, 278, // space - /
+		500, 500, 500, 500, 500, 500, 500, 500, 500, 500, // 0 - 9
+		278, 278, 564, 564, 564, 444, 921, // : - @
+		722, 667, 667, 722, 611, 556, 722, 722, 333, 389, 722, 611, 889, // A - M
+		722, 722, 556, 722, 667, 556, 611, 722, 722, 944, 722, 722, 611, // N - Z
+		333, 278, 333, 469, 500, 333, // [ - `
+		444, 500, 444, 500, 444, 333, 500, 500, 278, 278, 500, 278, 778, // a - m
+		500, 500, 500, 500, 333, 389, 278, 500, 500, 722, 500, 500, 444, // n - z
+		480, 200, 480, 541, // { - ~
+	},
+	fallback: 500,
+}
+
+// Courier metrics, for monospace fonts
+var Courier = &Face{
+	widths: [95]uint16{
+		600, 600, 600, 600, 600, 600, 600, 600, 600, 600, 600, 600, 600, 600, 600, 600,
+		600, 600, 600, 600, 600, 600, 600, 600, 600, 600,
+		600, 600, 600, 600, 600, 600, 600,
+		600, 600, 600, 600, 600, 600, 600, 600, 600, 600, 600, 600, 600,
+		600, 600, 600, 600, 600, 600, 600, 600, 600, 600, 600, 600, 600,
+		600, 600, 600, 600, 600, 600,
+		600, 600, 600, 600, 600, 600, 600, 600, 600, 600, 600, 600, 600,
+		600, 600, 600, 600, 600, 600, 600, 600, 600, 600, 600, 600, 600,
+		600, 600, 600, 600,
+	},
+	fallback: 600,
+}
+
+// ForFamily returns the face best matching a CSS font family list,
+// like "Georgia, serif". Unknown families use Helvetica metrics.
+func ForFamily(family string) *Face {
+	for _, name := range strings.Split(family, ",") {
+		name = strings.ToLower(strings.Trim(strings.TrimSpace(name), `"'`))
+		switch name {
+		case "sans-serif", "helvetica", "arial", "verdana", "system-ui":
+			return Helvetica
+		case "serif", "times", "times new roman", "georgia":
+			return Times
+		case "monospace", "courier", "courier new", "menlo", "consolas":
+			return Courier
+		}
+	}
+	return Helvetica
+}
+
+// EmWidth returns the width of one line of text, in em units
+func (f *Face) EmWidth(text string) float64 {
+	var width int
+	for _, r := range text {
+		width += int(f.advance(r))
+	}
+	return float64(width) / 1000
+}
+
+// Width returns the width in pixels of one line of text,
+// using a font of the given pixel size
+func (f *Face) Width(text string, size float64) float64 {
+	return f.EmWidth(text) * size
+}
+
+// Size returns the width and height in pixels of text,
+// using a font of the given pixel size.
+// Lines are separated by newlines, and spaced 1em apart.
+func (f *Face) Size(text string, size float64) (width, height float64) {
+	lines := strings.Split(text, "\n")
+	for _, line := range lines {
+		if lineWidth := f.Width(line, size); lineWidth > width {
+			width = lineWidth
+		}
+	}
+	return width, float64(len(lines)) * size
+}
+
+func (f *Face) advance(r rune) uint16 {
+	switch {
+	case r >= ' ' && r <= '~':
+		return f.widths[r-' ']
+	case isWide(r):
+		return 1000
+	default:
+		return f.fallback
+	}
+}
+
+// isWide reports if a character is typically drawn at full em width,
+// like the characters of East Asian scripts
+func isWide(r rune) bool {
+	return r >= 0x1100 && r <= 0x115F ||
+		r >= 0x2E80 && r <= 0xA4CF ||
+		r >= 0xAC00 && r <= 0xD7A3 ||
+		r >= 0xF900 && r <= 0xFAFF ||
+		r >= 0xFF00 && r <= 0xFF60
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/erkkah/margaid/xt"
+)
+
+func TestWidth(t *testing.T) {
+	x := xt.X(t)
+
+	x.Equal(Helvetica.Width("Hello", 10), 22.78)
+	x.Equal(Courier.Width("Hello", 10), 30.0)
+	x.Assert(Times.Width("Hello", 10) < Helvetica.Width("Hello", 10))
+	x.Equal(Helvetica.Width("", 10), 0.0)
+}
+
+func TestSize(t *testing.T) {
+	x := xt.X(t)
+
+	width, height := Courier.Size("ab\nabcd", 10)
+	x.Equal(width, 24.0)
+	x.Equal(height, 20.0)
+}
+
+func TestForFamily(t *testing.T) {
+	x := xt.X(t)
+
+	x.Equal(ForFamily("sans-serif"), Helvetica)
+	x.Equal(ForFamily("'Fancy Font', Georgia, serif"), Times)
+	x.Equal(ForFamily("Monospace"), Courier)
+	x.Equal(ForFamily("Unknown"), Helvetica)
+}
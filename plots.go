@@ -3,16 +3,33 @@ package margaid
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 
 	"github.com/erkkah/margaid/svg"
 )
 
 type plotOptions struct {
-	xAxis       Axis
-	yAxis       Axis
-	marker      string
-	strokeWidth float32
+	xAxis         Axis
+	yAxis         Axis
+	marker        string
+	strokeWidth   float32
+	interpolation Interpolation
+	baseline      *float64
+	fill          string
+	stacked       bool
+	horizontal    bool
+	errorBars     bool
+	band          bool
+	rising        string
+	falling       string
+	innerRadius   float64
+	pieLabels     PieLabels
+	colormap      Colormap
+	bubbleSize    float64
+	color         string
+	dash          string
+	arrow         *struct{ X, Y float64 }
 }
 
 // Using is the base type for plotting options
@@ -20,9 +37,15 @@ type Using func(*plotOptions)
 
 func getPlotOptions(using []Using) plotOptions {
 	options := plotOptions{
-		xAxis:       XAxis,
-		yAxis:       YAxis,
-		strokeWidth: 3,
+		xAxis:         XAxis,
+		yAxis:         YAxis,
+		strokeWidth:   3,
+		interpolation: Straight,
+		rising:        "hsl(140, 50%, 45%)",
+		falling:       "hsl(0, 65%, 55%)",
+		pieLabels:     PercentLabels,
+		colormap:      Viridis,
+		bubbleSize:    defaultBubbleSize,
 	}
 
 	for _, u := range using {
@@ -48,14 +71,173 @@ func UsingMarker(marker string) Using {
 	}
 }
 
+// UsingStrokeWidth sets the stroke width in pixels
 func UsingStrokeWidth(width float32) Using {
 	return func(o *plotOptions) {
 		o.strokeWidth = width
 	}
 }
 
+// UsingStacked stacks bars on top of each other in series order,
+// instead of placing them side by side.
+func UsingStacked() Using {
+	return func(o *plotOptions) {
+		o.stacked = true
+	}
+}
+
+// UsingHorizontal draws bars growing along the x axis from
+// categories on the y axis. Each value is drawn as a bar of length X
+// at the position Y.
+func UsingHorizontal() Using {
+	return func(o *plotOptions) {
+		o.horizontal = true
+	}
+}
+
+// UsingErrorBars draws whiskers showing the bounds of each value,
+// see Value.WithYBounds and Value.WithXBounds.
+// Applies to line, smooth, step and scatter plots.
+func UsingErrorBars() Using {
+	return func(o *plotOptions) {
+		o.errorBars = true
+	}
+}
+
+// UsingBand draws a shaded band between the lower and upper y bounds
+// of the values, see Value.WithYBounds and UsingFill.
+// Applies to line, smooth, step and scatter plots.
+func UsingBand() Using {
+	return func(o *plotOptions) {
+		o.band = true
+	}
+}
+
+// UsingRiseFallColors sets the candlestick colors for rising and
+// falling periods, as valid SVG color attribute strings.
+func UsingRiseFallColors(rising, falling string) Using {
+	return func(o *plotOptions) {
+		o.rising = rising
+		o.falling = falling
+	}
+}
+
+// UsingInnerRadius sets the inner radius of pie charts as a fraction
+// [0..1) of the outer radius, turning the pie into a donut.
+func UsingInnerRadius(fraction float64) Using {
+	return func(o *plotOptions) {
+		o.innerRadius = math.Max(0, math.Min(0.95, fraction))
+	}
+}
+
+// PieLabels is the type for the pie slice label constants
+type PieLabels int
+
+// PieLabels constants
+const (
+	PercentLabels PieLabels = iota + 'c'
+	TitleLabels
+	NoLabels
+)
+
+// UsingPieLabels selects how pie slices are labeled.
+// Slices are labeled with their percentage by default.
+func UsingPieLabels(labels PieLabels) Using {
+	return func(o *plotOptions) {
+		o.pieLabels = labels
+	}
+}
+
+// UsingColormap selects the colormap for heatmaps and color bars
+func UsingColormap(colormap Colormap) Using {
+	return func(o *plotOptions) {
+		o.colormap = colormap
+	}
+}
+
+// UsingBubbleSize sets the radius in pixels of the largest bubble in
+// bubble plots and size legends
+func UsingBubbleSize(radius float64) Using {
+	return func(o *plotOptions) {
+		o.bubbleSize = radius
+	}
+}
+
+// UsingColor sets the color of reference lines and annotations
+// as a valid SVG color attribute string.
+func UsingColor(color string) Using {
+	return func(o *plotOptions) {
+		o.color = color
+	}
+}
+
+// UsingDash sets the dash pattern of reference lines and annotations
+// as a valid SVG stroke-dasharray attribute string, like "4 2".
+func UsingDash(pattern string) Using {
+	return func(o *plotOptions) {
+		o.dash = pattern
+	}
+}
+
+// UsingArrow places annotation labels dx, dy pixels away from the
+// annotated point, with positive dy downwards, and draws an arrow
+// from the label to the point.
+func UsingArrow(dx, dy float64) Using {
+	return func(o *plotOptions) {
+		o.arrow = &struct{ X, Y float64 }{dx, dy}
+	}
+}
+
+// Interpolation is the type for the interpolation constants
+type Interpolation int
+
+// Interpolation constants
+const (
+	// Straight connects values using straight lines
+	Straight Interpolation = iota + 'i'
+	// Curved connects values using smooth Catmull-Rom curves
+	Curved
+	// StepBefore changes to each new value at the previous x position
+	StepBefore
+	// StepAfter keeps each value until the next x position
+	StepAfter
+	// StepMid changes value halfway between x positions
+	StepMid
+)
+
+func (i Interpolation) isStep() bool {
+	return i == StepBefore || i == StepAfter || i == StepMid
+}
+
+// UsingInterpolation selects how values are connected in area and step plots
+func UsingInterpolation(interpolation Interpolation) Using {
+	return func(o *plotOptions) {
+		o.interpolation = interpolation
+	}
+}
+
+// UsingBaseline sets the y axis value that area plots are filled down to.
+// The default is the bottom of the plotting area, or zero for stacked areas.
+func UsingBaseline(baseline float64) Using {
+	return func(o *plotOptions) {
+		o.baseline = &baseline
+	}
+}
+
+// UsingFill sets the fill color as a valid SVG color attribute string.
+// The default is a translucent version of the plot color.
+func UsingFill(color string) Using {
+	return func(o *plotOptions) {
+		o.fill = color
+	}
+}
+
 // Line draws a series using straight lines
 func (m *Margaid) Line(series *Series, using ...Using) {
+	if m.record(func() { m.Line(series, using...) }) {
+		return
+	}
+
 	options := getPlotOptions(using)
 
 	points, err := m.getProjectedValues(series, options.xAxis, options.yAxis)
@@ -66,22 +248,29 @@ func (m *Margaid) Line(series *Series, using ...Using) {
 
 	id := m.addPlot(series.title)
 	color := m.getPlotColor(id)
+	m.drawBounds(series, options, id, Straight)
 	m.g.
 		StrokeWidth(fmt.Sprintf("%vpx", options.strokeWidth)).
 		Fill("none").
 		Stroke(color).
 		Marker(options.marker).
 		Transform(
-			svg.Translation(m.inset, m.height-m.inset),
+			svg.Translation(m.insets.left, m.height-m.insets.bottom),
 			svg.Scaling(1, -1),
 		).
+		Clip(m.plotClip()).
 		Polyline(points...).
 		Marker("").
-		Transform()
+		Transform().
+		Clip("")
 }
 
 // Smooth draws one series as a smooth curve
 func (m *Margaid) Smooth(series *Series, using ...Using) {
+	if m.record(func() { m.Smooth(series, using...) }) {
+		return
+	}
+
 	options := getPlotOptions(using)
 
 	points, err := m.getProjectedValues(series, options.xAxis, options.yAxis)
@@ -92,33 +281,268 @@ func (m *Margaid) Smooth(series *Series, using ...Using) {
 
 	id := m.addPlot(series.title)
 	color := m.getPlotColor(id)
+	m.drawBounds(series, options, id, Curved)
 	m.g.
 		StrokeWidth(fmt.Sprintf("%vpx", options.strokeWidth)).
 		Fill("none").
 		Stroke(color).
 		Marker(options.marker).
 		Transform(
-			svg.Translation(m.inset, m.height-m.inset),
+			svg.Translation(m.insets.left, m.height-m.insets.bottom),
 			svg.Scaling(1, -1),
-		)
+		).
+		Clip(m.plotClip()).
+		Path(smoothPath(points)).
+		Marker("").
+		Transform().
+		Clip("")
+}
 
-	var path strings.Builder
+// Step draws a series as a step function, keeping each value until
+// the next x position. Use UsingInterpolation with StepBefore or StepMid
+// to place the steps differently.
+func (m *Margaid) Step(series *Series, using ...Using) {
+	if m.record(func() { m.Step(series, using...) }) {
+		return
+	}
 
-	path.WriteString(fmt.Sprintf("M%e,%e ", points[0].X, points[0].Y))
-	catmull := catmullRom2bezier(points)
+	if m.notPolar("step plots") {
+		return
+	}
 
-	for _, p := range catmull {
-		path.WriteString(fmt.Sprintf("C%e,%e %e,%e %e,%e ",
-			p[0].X, p[0].Y,
-			p[1].X, p[1].Y,
-			p[2].X, p[2].Y,
-		))
+	options := getPlotOptions(append([]Using{UsingInterpolation(StepAfter)}, using...))
+
+	points, err := m.getProjectedValues(series, options.xAxis, options.yAxis)
+	if err != nil {
+		m.error(err.Error())
+		return
+	}
+
+	id := m.addPlot(series.title)
+	color := m.getPlotColor(id)
+	m.drawBounds(series, options, id, options.interpolation)
+	m.g.Transform(
+		svg.Translation(m.insets.left, m.height-m.insets.bottom),
+		svg.Scaling(1, -1),
+	).
+		Clip(m.plotClip())
+	m.drawOutline(points, options, color)
+	m.g.Transform().
+		Clip("")
+}
+
+// Area draws a series as a line and fills the area between the line
+// and a baseline, see UsingBaseline and UsingFill.
+// Values are connected using straight lines by default,
+// see UsingInterpolation.
+func (m *Margaid) Area(series *Series, using ...Using) {
+	if m.record(func() { m.Area(series, using...) }) {
+		return
+	}
+
+	if m.notPolar("area plots") {
+		return
+	}
+
+	options := getPlotOptions(using)
+
+	points, err := m.getProjectedValues(series, options.xAxis, options.yAxis)
+	if err != nil {
+		m.error(err.Error())
+		return
+	}
+
+	baseline := 0.0
+	if options.baseline != nil {
+		baseline, err = m.project(*options.baseline, options.yAxis)
+		if err != nil {
+			m.error(err.Error())
+			return
+		}
+	}
+
+	id := m.addPlot(series.title)
+	if len(points) < 2 {
+		return
+	}
+	color := m.getPlotColor(id)
+	fill := options.fill
+	if fill == "" {
+		fill = m.getPlotFillColor(id)
+	}
+
+	first := points[0]
+	last := points[len(points)-1]
+
+	m.g.
+		Fill(fill).
+		Stroke("none").
+		Transform(
+			svg.Translation(m.insets.left, m.height-m.insets.bottom),
+			svg.Scaling(1, -1),
+		).
+		Clip(m.plotClip())
+	m.drawCurve(points, options.interpolation, []struct{ X, Y float64 }{
+		{last.X, baseline},
+		{first.X, baseline},
+	}...)
+
+	m.drawOutline(points, options, color)
+	m.g.Transform().
+		Clip("")
+}
+
+// Scatter draws a series as separate markers, without connecting lines.
+// The marker defaults to "filled-circle".
+func (m *Margaid) Scatter(series *Series, using ...Using) {
+	if m.record(func() { m.Scatter(series, using...) }) {
+		return
+	}
+
+	options := getPlotOptions(using)
+
+	points, err := m.getProjectedValues(series, options.xAxis, options.yAxis)
+	if err != nil {
+		m.error(err.Error())
+		return
+	}
+
+	marker := options.marker
+	if marker == "" {
+		marker = "filled-circle"
+	}
+
+	id := m.addMarkerPlot(series.title, marker)
+	color := m.getPlotColor(id)
+	m.drawBounds(series, options, id, Straight)
+	m.g.
+		StrokeWidth("1px").
+		Transform(
+			svg.Translation(m.insets.left, m.height-m.insets.bottom),
+			svg.Scaling(1, -1),
+		).
+		Clip(m.plotClip())
+
+	for _, p := range points {
+		m.drawMarker(marker, color, p.X, p.Y, markerSize)
+	}
+	m.g.Transform().
+		Clip("")
+}
+
+// Bubble draws a series of triples as circles, with the circle area
+// scaled by the z value, see MakeTriple.
+// The largest z value of the series gets the radius set by UsingBubbleSize.
+// See also SizeLegend.
+func (m *Margaid) Bubble(series *Series, using ...Using) {
+	if m.record(func() { m.Bubble(series, using...) }) {
+		return
+	}
+
+	options := getPlotOptions(using)
+
+	points, err := m.getProjectedValues(series, options.xAxis, options.yAxis)
+	if err != nil {
+		m.error(err.Error())
+		return
+	}
+
+	var sizes []float64
+	values := series.Values()
+	for values.Next() {
+		sizes = append(sizes, values.Get().Z)
+	}
+
+	id := m.addMarkerPlot(series.title, "filled-circle")
+	m.g.
+		StrokeWidth("1px").
+		Fill(m.getPlotFillColor(id)).
+		Stroke(m.getPlotColor(id)).
+		Transform(
+			svg.Translation(m.insets.left, m.height-m.insets.bottom),
+			svg.Scaling(1, -1),
+		).
+		Clip(m.plotClip())
+
+	for i, p := range points {
+		radius := bubbleRadius(sizes[i], series.MaxZ(), options.bubbleSize)
+		if radius > 0 {
+			m.g.Circle(p.X, p.Y, radius)
+		}
+	}
+	m.g.Transform().
+		Clip("")
+}
+
+// bubbleRadius scales a bubble so that its area is proportional to its size
+func bubbleRadius(size, maxSize, maxRadius float64) float64 {
+	if size <= 0 || maxSize <= 0 {
+		return 0
+	}
+	return maxRadius * math.Sqrt(size/maxSize)
+}
+
+// Radar draws the specified group of series as closed, filled outlines
+// in polar coordinates, see WithPolar.
+// Each x value is a category, placed around the circle. Set the x axis
+// range to [0, n] for n categories numbered from 0 to spread them evenly.
+func (m *Margaid) Radar(series []*Series, using ...Using) {
+	if m.record(func() { m.Radar(series, using...) }) {
+		return
+	}
+
+	if !m.polar {
+		m.error("radar charts need polar coordinates, see WithPolar")
+		return
+	}
+
+	options := getPlotOptions(using)
+
+	for _, s := range series {
+		points, err := m.getProjectedValues(s, options.xAxis, options.yAxis)
+		if err != nil {
+			m.error(err.Error())
+			return
+		}
+
+		id := m.addPlot(s.title)
+		if len(points) < 2 {
+			continue
+		}
+		fill := options.fill
+		if fill == "" {
+			fill = m.getPlotFillColor(id)
+		}
+
+		m.g.
+			StrokeWidth(fmt.Sprintf("%vpx", options.strokeWidth)).
+			Fill(fill).
+			Stroke(m.getPlotColor(id)).
+			Marker(options.marker).
+			Transform(
+				svg.Translation(m.insets.left, m.height-m.insets.bottom),
+				svg.Scaling(1, -1),
+			).
+			Clip(m.plotClip()).
+			Polyline(append(points, points[0])...).
+			Marker("")
 	}
-	m.g.Path(path.String()).Marker("").Transform()
+	m.g.Transform().
+		Clip("")
 }
 
 // Bar draws bars for the specified group of series.
+// Bars are placed side by side, or stacked if UsingStacked is specified.
+// Bars are vertical, unless UsingHorizontal is specified.
 func (m *Margaid) Bar(series []*Series, using ...Using) {
+	if m.record(func() { m.Bar(series, using...) }) {
+		return
+	}
+
+	if m.notPolar("bar charts") {
+		return
+	}
+
 	if len(series) == 0 {
 		return
 	}
@@ -132,15 +556,40 @@ func (m *Margaid) Bar(series []*Series, using ...Using) {
 		}
 	}
 
-	plotWidth := (m.width - 2*m.inset)
+	plotWidth := m.plotWidth()
+	valueAxis := options.yAxis
+	if options.horizontal {
+		plotWidth = m.plotHeight()
+		valueAxis = options.xAxis
+	}
 	barWidth := plotWidth / float64(maxSize)
 	barWidth /= 1.5
 	barWidth = math.Min(barWidth, tickDistance)
-	barWidth /= float64(len(series))
-	barOffset := -(barWidth / 2) * float64(len(series)-1)
+	barOffset := 0.0
+
+	var lower, upper [][]Value
+	if options.stacked {
+		lower, upper = stack(series, options.horizontal)
+	} else {
+		barWidth /= float64(len(series))
+		barOffset = -(barWidth / 2) * float64(len(series)-1)
+	}
 
 	for i, s := range series {
-		points, err := m.getProjectedValues(s, options.xAxis, options.yAxis)
+		var points []struct{ X, Y float64 }
+		var err error
+		bases := make([]float64, s.Size())
+		offset := barOffset + float64(i)*barWidth
+
+		if options.stacked {
+			offset = 0
+			points, err = m.projectValues(upper[i], options.xAxis, options.yAxis)
+			if err == nil {
+				bases, err = m.projectStackBases(lower[i], valueAxis, 0, i == 0)
+			}
+		} else {
+			points, err = m.getProjectedValues(s, options.xAxis, options.yAxis)
+		}
 
 		if err != nil {
 			m.error(err.Error())
@@ -152,16 +601,746 @@ func (m *Margaid) Bar(series []*Series, using ...Using) {
 			StrokeWidth("1px").
 			Color(color).
 			Transform(
-				svg.Translation(m.inset, m.height-m.inset),
+				svg.Translation(m.insets.left, m.height-m.insets.bottom),
 				svg.Scaling(1, -1),
-			)
+			).
+			Clip(m.plotClip())
 
-		for _, p := range points {
-			m.g.Rect(barOffset+float64(i)*barWidth+p.X-barWidth/2, 0, barWidth, p.Y)
+		// Stacked negative values give bars extending down, or to the left,
+		// from their base. Rect handles the negative extents.
+		for j, p := range points {
+			if options.horizontal {
+				// First series on top
+				m.g.Rect(bases[j], -offset+p.Y-barWidth/2, p.X-bases[j], barWidth)
+			} else {
+				m.g.Rect(offset+p.X-barWidth/2, bases[j], barWidth, p.Y-bases[j])
+			}
 		}
 	}
-	m.g.Transform()
+	m.g.Transform().
+		Clip("")
+
+}
+
+// Candlestick draws open-high-low-close values as candles, see MakeOHLCValue
+// and the OHLC aggregator. The candle body spans the open and close values,
+// and the wick spans the low and high values.
+// Values without y bounds, like those of a plain series, are reported as errors.
+// Rising and falling periods are colored as set by UsingRiseFallColors.
+func (m *Margaid) Candlestick(series *Series, using ...Using) {
+	if m.record(func() { m.Candlestick(series, using...) }) {
+		return
+	}
 
+	if m.notPolar("candlestick charts") {
+		return
+	}
+
+	options := getPlotOptions(using)
+
+	var opens, closes []Value
+	values := series.Values()
+	for values.Next() {
+		v := values.Get()
+		if v.YBounds == nil {
+			m.error("Candlestick needs OHLC values, see MakeOHLCValue")
+			return
+		}
+		opens = append(opens, MakeValue(v.X, v.Open))
+		closes = append(closes, v)
+	}
+
+	openPoints, err := m.projectValues(opens, options.xAxis, options.yAxis)
+	if err != nil {
+		m.error(err.Error())
+		return
+	}
+	closePoints, err := m.projectValues(closes, options.xAxis, options.yAxis)
+	if err != nil {
+		m.error(err.Error())
+		return
+	}
+	lower, upper, err := m.getProjectedBounds(series, options.xAxis, options.yAxis)
+	if err != nil {
+		m.error(err.Error())
+		return
+	}
+
+	id := m.addPlot(series.title)
+	m.plots[id].color = options.rising
+
+	plotWidth := m.plotWidth()
+	candleWidth := plotWidth / math.Max(1, float64(series.Size()))
+	candleWidth /= 1.5
+	candleWidth = math.Min(candleWidth, tickDistance)
+
+	m.g.
+		StrokeWidth("1px").
+		Transform(
+			svg.Translation(m.insets.left, m.height-m.insets.bottom),
+			svg.Scaling(1, -1),
+		).
+		Clip(m.plotClip())
+
+	for i, c := range closePoints {
+		o := openPoints[i]
+		color := options.rising
+		if closes[i].Y < closes[i].Open {
+			color = options.falling
+		}
+		low := math.Min(lower[i].Y, math.Min(o.Y, c.Y))
+		high := math.Max(upper[i].Y, math.Max(o.Y, c.Y))
+
+		m.g.Color(color).
+			Polyline([]struct{ X, Y float64 }{
+				{c.X, low}, {c.X, high},
+			}...).
+			Rect(c.X-candleWidth/2, math.Min(o.Y, c.Y), candleWidth, math.Max(1, math.Abs(c.Y-o.Y)))
+	}
+	m.g.Transform().
+		Clip("")
+}
+
+// Box draws box-and-whisker plots for the specified group of series.
+// The y values of each series are grouped by x value, and each group is
+// drawn at its x position, showing the median, the quartiles, whiskers
+// reaching the most extreme values within 1.5 IQR of the quartiles,
+// and outliers beyond the whiskers.
+// The boxes of different series are placed side by side.
+func (m *Margaid) Box(series []*Series, using ...Using) {
+	if m.record(func() { m.Box(series, using...) }) {
+		return
+	}
+
+	if m.notPolar("box plots") {
+		return
+	}
+
+	if len(series) == 0 {
+		return
+	}
+
+	options := getPlotOptions(using)
+
+	maxGroups := 1
+	for _, s := range series {
+		xValues, _ := s.groupByX()
+		if len(xValues) > maxGroups {
+			maxGroups = len(xValues)
+		}
+	}
+
+	plotWidth := m.plotWidth()
+	boxWidth := plotWidth / float64(maxGroups)
+	boxWidth /= 1.5
+	boxWidth = math.Min(boxWidth, tickDistance)
+	boxWidth /= float64(len(series))
+	boxOffset := -(boxWidth / 2) * float64(len(series)-1)
+
+	for i, s := range series {
+		xValues, groups := s.groupByX()
+
+		id := m.addPlot(s.title)
+		color := m.getPlotColor(id)
+		offset := boxOffset + float64(i)*boxWidth
+
+		m.g.Transform(
+			svg.Translation(m.insets.left, m.height-m.insets.bottom),
+			svg.Scaling(1, -1),
+		).
+			Clip(m.plotClip())
+
+		for _, x := range xValues {
+			group := groups[x]
+
+			q1 := quantile(group, 0.25)
+			median := quantile(group, 0.5)
+			q3 := quantile(group, 0.75)
+			iqr := q3 - q1
+
+			low := median
+			high := median
+			var outliers []Value
+			for _, y := range group {
+				if y < q1-1.5*iqr || y > q3+1.5*iqr {
+					outliers = append(outliers, MakeValue(x, y))
+					continue
+				}
+				low = math.Min(low, y)
+				high = math.Max(high, y)
+			}
+
+			points, err := m.projectValues([]Value{
+				MakeValue(x, low),
+				MakeValue(x, q1),
+				MakeValue(x, median),
+				MakeValue(x, q3),
+				MakeValue(x, high),
+			}, options.xAxis, options.yAxis)
+			if err != nil {
+				m.error(err.Error())
+				return
+			}
+			outlierPoints, err := m.projectValues(outliers, options.xAxis, options.yAxis)
+			if err != nil {
+				m.error(err.Error())
+				return
+			}
+
+			center := points[0].X + offset
+			left := center - boxWidth/2
+			right := center + boxWidth/2
+			whisker := boxWidth / 4
+
+			m.g.
+				StrokeWidth("1px").
+				Fill(m.getPlotFillColor(id)).
+				Stroke(color).
+				Rect(left, points[1].Y, boxWidth, points[3].Y-points[1].Y)
+
+			m.g.
+				Fill("none").
+				Polyline([]struct{ X, Y float64 }{
+					{center, points[0].Y}, {center, points[1].Y},
+				}...).
+				Polyline([]struct{ X, Y float64 }{
+					{center, points[3].Y}, {center, points[4].Y},
+				}...).
+				Polyline([]struct{ X, Y float64 }{
+					{center - whisker, points[0].Y}, {center + whisker, points[0].Y},
+				}...).
+				Polyline([]struct{ X, Y float64 }{
+					{center - whisker, points[4].Y}, {center + whisker, points[4].Y},
+				}...)
+
+			m.g.
+				StrokeWidth("2px").
+				Polyline([]struct{ X, Y float64 }{
+					{left, points[2].Y}, {right, points[2].Y},
+				}...)
+
+			m.g.StrokeWidth("1px")
+			for _, p := range outlierPoints {
+				m.drawMarker("circle", color, p.X+offset, p.Y, markerSize)
+			}
+		}
+	}
+	m.g.Transform().
+		Clip("")
+}
+
+// Histogram draws a series as adjacent bars without gaps, as created by
+// NewHistogram. Each bar spans the x bounds of its value. Values without
+// x bounds span halfway to their neighbors.
+func (m *Margaid) Histogram(series *Series, using ...Using) {
+	if m.record(func() { m.Histogram(series, using...) }) {
+		return
+	}
+
+	if m.notPolar("histograms") {
+		return
+	}
+
+	options := getPlotOptions(using)
+
+	var values []Value
+	iterator := series.Values()
+	for iterator.Next() {
+		values = append(values, iterator.Get())
+	}
+
+	var lower, upper []Value
+	for i, v := range values {
+		low := v.X
+		high := v.X
+		if v.XBounds != nil {
+			low = v.XBounds.Low
+			high = v.XBounds.High
+		} else {
+			if i > 0 {
+				low = (values[i-1].X + v.X) / 2
+			}
+			if i < len(values)-1 {
+				high = (v.X + values[i+1].X) / 2
+			}
+			if i == 0 {
+				low = v.X - (high - v.X)
+			}
+			if i == len(values)-1 {
+				high = v.X + (v.X - low)
+			}
+		}
+		lower = append(lower, MakeValue(low, v.Y))
+		upper = append(upper, MakeValue(high, v.Y))
+	}
+
+	lowerPoints, err := m.projectValues(lower, options.xAxis, options.yAxis)
+	if err != nil {
+		m.error(err.Error())
+		return
+	}
+	upperPoints, err := m.projectValues(upper, options.xAxis, options.yAxis)
+	if err != nil {
+		m.error(err.Error())
+		return
+	}
+
+	id := m.addPlot(series.title)
+	m.g.
+		StrokeWidth("1px").
+		Fill(m.getPlotFillColor(id)).
+		Stroke(m.getPlotColor(id)).
+		Transform(
+			svg.Translation(m.insets.left, m.height-m.insets.bottom),
+			svg.Scaling(1, -1),
+		).
+		Clip(m.plotClip())
+
+	for i, low := range lowerPoints {
+		m.g.Rect(low.X, 0, upperPoints[i].X-low.X, low.Y)
+	}
+	m.g.Transform().
+		Clip("")
+}
+
+// Pie draws a pie chart with one slice for each series in the specified group.
+// The size of each slice is the sum of the y values of its series,
+// ignoring negative values. Series summing to zero get no slice and
+// no legend entry.
+// Slices are drawn clockwise from the top.
+// See UsingInnerRadius for donut charts and UsingPieLabels for slice labels.
+func (m *Margaid) Pie(series []*Series, using ...Using) {
+	if m.record(func() { m.Pie(series, using...) }) {
+		return
+	}
+
+	if m.notPolar("pie charts") {
+		return
+	}
+
+	options := getPlotOptions(using)
+
+	var sums []float64
+	total := 0.0
+	for _, s := range series {
+		sum := 0.0
+		values := s.Values()
+		for values.Next() {
+			sum += math.Max(0, values.Get().Y)
+		}
+		sums = append(sums, sum)
+		total += sum
+	}
+
+	plotWidth := m.plotWidth()
+	plotHeight := m.plotHeight()
+	cx := plotWidth / 2
+	cy := plotHeight / 2
+	radius := math.Min(plotWidth, plotHeight) / 2 * (1 - m.padding)
+	innerRadius := radius * options.innerRadius
+
+	type label struct {
+		x, y float64
+		text string
+	}
+	var labels []label
+
+	angle := -90.0
+	for i, s := range series {
+		if total == 0 || sums[i] == 0 {
+			continue
+		}
+		id := m.addPlot(s.title)
+
+		sweep := 360 * sums[i] / total
+		m.g.
+			StrokeWidth("1px").
+			Color(m.getPlotColor(id)).
+			Transform(svg.Translation(m.insets.left, m.insets.top)).
+			Sector(cx, cy, radius, innerRadius, angle, angle+sweep)
+
+		mid := (angle + sweep/2) * math.Pi / 180
+		labelRadius := (radius + innerRadius) / 2
+		if innerRadius == 0 {
+			labelRadius = radius * 0.65
+		}
+		text := ""
+		switch options.pieLabels {
+		case PercentLabels:
+			text = fmt.Sprintf("%.0f%%", 100*sums[i]/total)
+		case TitleLabels:
+			text = s.title
+		}
+		if text != "" {
+			labels = append(labels, label{
+				x:    cx + labelRadius*math.Cos(mid),
+				y:    cy + labelRadius*math.Sin(mid),
+				text: text,
+			})
+		}
+
+		angle += sweep
+	}
+
+	m.g.
+		Font(m.labelFamily, fmt.Sprintf("%dpx", m.labelSize)).
+		FontStyle(svg.StyleNormal, svg.WeightNormal).
+		Alignment(svg.HAlignMiddle, svg.VAlignCentral).
+		Fill("black")
+	for _, l := range labels {
+		m.g.Text(l.x, l.y, svg.EncodeTextFor(l.text, svg.HAlignMiddle, m.labelFamily))
+	}
+	m.g.Transform().
+		Clip("")
+}
+
+// Heatmap draws a series of triples as colored cells centered at each
+// x, y position, see MakeTriple and Series.Matrix.
+// Cell sizes are set by the smallest distance between x and y positions.
+// Cells are colored by their z value, relative to the z range of the series,
+// using the colormap selected by UsingColormap. See also ColorBar.
+func (m *Margaid) Heatmap(series *Series, using ...Using) {
+	if m.record(func() { m.Heatmap(series, using...) }) {
+		return
+	}
+
+	if m.notPolar("heatmaps") {
+		return
+	}
+
+	options := getPlotOptions(using)
+
+	var values []Value
+	var xValues, yValues []float64
+	iterator := series.Values()
+	for iterator.Next() {
+		v := iterator.Get()
+		values = append(values, v)
+		xValues = append(xValues, v.X)
+		yValues = append(yValues, v.Y)
+	}
+
+	cellWidth := smallestGap(xValues)
+	cellHeight := smallestGap(yValues)
+	zRange := series.MaxZ() - series.MinZ()
+
+	m.g.
+		StrokeWidth("0.5px").
+		Transform(
+			svg.Translation(m.insets.left, m.height-m.insets.bottom),
+			svg.Scaling(1, -1),
+		).
+		Clip(m.plotClip())
+
+	for _, v := range values {
+		corners, err := m.projectValues([]Value{
+			MakeValue(v.X-cellWidth/2, v.Y-cellHeight/2),
+			MakeValue(v.X+cellWidth/2, v.Y+cellHeight/2),
+		}, options.xAxis, options.yAxis)
+		if err != nil {
+			m.error(err.Error())
+			return
+		}
+
+		level := 0.5
+		if zRange != 0 {
+			level = (v.Z - series.MinZ()) / zRange
+		}
+		m.g.
+			Color(options.colormap(level)).
+			Rect(corners[0].X, corners[0].Y, corners[1].X-corners[0].X, corners[1].Y-corners[0].Y)
+	}
+	m.g.Transform().
+		Clip("")
+}
+
+// smallestGap returns the smallest non-zero distance between values,
+// or 1.0 if there is none.
+func smallestGap(values []float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	gap := math.Inf(1)
+	for i := 1; i < len(sorted); i++ {
+		if distance := sorted[i] - sorted[i-1]; distance > 0 {
+			gap = math.Min(gap, distance)
+		}
+	}
+	if math.IsInf(gap, 1) {
+		return 1
+	}
+	return gap
+}
+
+// StackedArea draws areas for the specified group of series, stacked
+// on top of each other in series order, starting at zero or at the value
+// set by UsingBaseline. Negative values stack downwards from zero.
+// See Area for options.
+func (m *Margaid) StackedArea(series []*Series, using ...Using) {
+	if m.record(func() { m.StackedArea(series, using...) }) {
+		return
+	}
+
+	if m.notPolar("area plots") {
+		return
+	}
+
+	options := getPlotOptions(using)
+
+	baseline := 0.0
+	if options.baseline != nil {
+		baseline = *options.baseline
+	}
+
+	lower, upper := stack(series, false)
+
+	for i, s := range series {
+		points, err := m.projectValues(upper[i], options.xAxis, options.yAxis)
+		if err != nil {
+			m.error(err.Error())
+			return
+		}
+		bases, err := m.projectStackBases(lower[i], options.yAxis, baseline, i == 0)
+		if err != nil {
+			m.error(err.Error())
+			return
+		}
+
+		// Register the plot even if it is not drawn,
+		// to keep the legend and colors in series order
+		id := m.addPlot(s.title)
+		if len(points) < 2 {
+			continue
+		}
+
+		var closing []struct{ X, Y float64 }
+		for j := len(points) - 1; j >= 0; j-- {
+			closing = append(closing, struct{ X, Y float64 }{points[j].X, bases[j]})
+		}
+
+		color := m.getPlotColor(id)
+		fill := options.fill
+		if fill == "" {
+			fill = m.getPlotFillColor(id)
+		}
+
+		m.g.
+			Fill(fill).
+			Stroke("none").
+			Transform(
+				svg.Translation(m.insets.left, m.height-m.insets.bottom),
+				svg.Scaling(1, -1),
+			).
+			Clip(m.plotClip())
+		m.drawCurve(points, options.interpolation, closing...)
+
+		m.drawOutline(points, options, color)
+	}
+	m.g.Transform().
+		Clip("")
+}
+
+// drawBounds draws error bars and bands for the value bounds of a
+// series, as selected by the plot options.
+func (m *Margaid) drawBounds(series *Series, options plotOptions, id int, interpolation Interpolation) {
+	if !options.errorBars && !options.band {
+		return
+	}
+
+	if m.notPolar("error bars and bands") {
+		return
+	}
+
+	points, err := m.getProjectedValues(series, options.xAxis, options.yAxis)
+	if err != nil {
+		m.error(err.Error())
+		return
+	}
+	lower, upper, err := m.getProjectedBounds(series, options.xAxis, options.yAxis)
+	if err != nil {
+		m.error(err.Error())
+		return
+	}
+
+	m.g.Transform(
+		svg.Translation(m.insets.left, m.height-m.insets.bottom),
+		svg.Scaling(1, -1),
+	).
+		Clip(m.plotClip())
+
+	if options.band && len(points) > 1 {
+		fill := options.fill
+		if fill == "" {
+			fill = m.getPlotFillColor(id)
+		}
+
+		var top, bottom []struct{ X, Y float64 }
+		for i, p := range points {
+			top = append(top, struct{ X, Y float64 }{p.X, upper[i].Y})
+		}
+		for i := len(points) - 1; i >= 0; i-- {
+			bottom = append(bottom, struct{ X, Y float64 }{points[i].X, lower[i].Y})
+		}
+
+		m.g.Fill(fill).Stroke("none")
+		m.drawCurve(top, interpolation, bottom...)
+	}
+
+	if options.errorBars {
+		m.g.
+			StrokeWidth("1px").
+			Fill("none").
+			Stroke(m.getPlotColor(id))
+
+		for i, p := range points {
+			low := lower[i]
+			high := upper[i]
+			if low.Y != high.Y {
+				m.g.Polyline([]struct{ X, Y float64 }{
+					{p.X, low.Y}, {p.X, high.Y},
+				}...).Polyline([]struct{ X, Y float64 }{
+					{p.X - markerSize, low.Y}, {p.X + markerSize, low.Y},
+				}...).Polyline([]struct{ X, Y float64 }{
+					{p.X - markerSize, high.Y}, {p.X + markerSize, high.Y},
+				}...)
+			}
+			if low.X != high.X {
+				m.g.Polyline([]struct{ X, Y float64 }{
+					{low.X, p.Y}, {high.X, p.Y},
+				}...).Polyline([]struct{ X, Y float64 }{
+					{low.X, p.Y - markerSize}, {low.X, p.Y + markerSize},
+				}...).Polyline([]struct{ X, Y float64 }{
+					{high.X, p.Y - markerSize}, {high.X, p.Y + markerSize},
+				}...)
+			}
+		}
+	}
+}
+
+// drawOutline strokes a curve through the points using the plot options.
+// Markers are only placed at the points, not at step corners.
+func (m *Margaid) drawOutline(points []struct{ X, Y float64 }, options plotOptions, color string) {
+	m.g.
+		StrokeWidth(fmt.Sprintf("%vpx", options.strokeWidth)).
+		Fill("none").
+		Stroke(color)
+
+	if !options.interpolation.isStep() {
+		m.g.Marker(options.marker)
+		m.drawCurve(points, options.interpolation)
+		m.g.Marker("")
+		return
+	}
+
+	m.drawCurve(points, options.interpolation)
+	for _, p := range points {
+		m.drawMarker(options.marker, color, p.X, p.Y, markerSize)
+	}
+}
+
+// drawCurve draws a path through the points using the specified
+// interpolation. Any closing points are connected to the end of the
+// path by a straight line, and then interpolated the same way.
+func (m *Margaid) drawCurve(points []struct{ X, Y float64 }, interpolation Interpolation, closing ...struct{ X, Y float64 }) {
+	if interpolation.isStep() {
+		points = stepPoints(points, interpolation)
+		closing = stepClosingPoints(closing, interpolation)
+	}
+
+	if interpolation == Curved {
+		path := smoothPath(points)
+		if len(closing) > 0 {
+			path += fmt.Sprintf("L%e,%e ", closing[0].X, closing[0].Y)
+			path += smoothSegments(closing)
+		}
+		m.g.Path(path)
+		return
+	}
+
+	var all []struct{ X, Y float64 }
+	all = append(all, points...)
+	all = append(all, closing...)
+	m.g.Polyline(all...)
+}
+
+// drawMarker draws one marker centered at x, y.
+// See svg.Marker for valid marker types.
+func (m *Margaid) drawMarker(marker string, color string, x, y, radius float64) {
+	switch marker {
+	case "circle":
+		m.g.Fill("none").Stroke(color).Circle(x, y, radius)
+	case "filled-circle":
+		m.g.Fill(color).Stroke("none").Circle(x, y, radius)
+	case "square":
+		m.g.Fill("none").Stroke(color).Rect(x-radius, y-radius, radius*2, radius*2)
+	case "filled-square":
+		m.g.Fill(color).Stroke("none").Rect(x-radius, y-radius, radius*2, radius*2)
+	}
+}
+
+// stepPoints adds the corner points of a step function between each
+// pair of points.
+func stepPoints(points []struct{ X, Y float64 }, interpolation Interpolation) []struct{ X, Y float64 } {
+	if len(points) == 0 {
+		return points
+	}
+
+	result := []struct{ X, Y float64 }{points[0]}
+
+	for i, p := range points[1:] {
+		previous := points[i]
+		switch interpolation {
+		case StepBefore:
+			result = append(result, struct{ X, Y float64 }{previous.X, p.Y})
+		case StepAfter:
+			result = append(result, struct{ X, Y float64 }{p.X, previous.Y})
+		case StepMid:
+			mid := (previous.X + p.X) / 2
+			result = append(result,
+				struct{ X, Y float64 }{mid, previous.Y},
+				struct{ X, Y float64 }{mid, p.Y},
+			)
+		}
+		result = append(result, p)
+	}
+
+	return result
+}
+
+// stepClosingPoints adds the corner points of a step function between
+// each pair of closing points. The closing points run backwards, so
+// the steps are flipped to match a curve drawn forwards.
+func stepClosingPoints(closing []struct{ X, Y float64 }, interpolation Interpolation) []struct{ X, Y float64 } {
+	reversed := interpolation
+	switch interpolation {
+	case StepBefore:
+		reversed = StepAfter
+	case StepAfter:
+		reversed = StepBefore
+	}
+	return stepPoints(closing, reversed)
+}
+
+// smoothPath builds SVG path commands for a smooth curve through the points
+func smoothPath(points []struct{ X, Y float64 }) string {
+	return fmt.Sprintf("M%e,%e ", points[0].X, points[0].Y) + smoothSegments(points)
+}
+
+// smoothSegments builds curve commands for a smooth curve from the first
+// point through the rest of the points
+func smoothSegments(points []struct{ X, Y float64 }) string {
+	var path strings.Builder
+
+	catmull := catmullRom2bezier(points)
+
+	for _, p := range catmull {
+		path.WriteString(fmt.Sprintf("C%e,%e %e,%e %e,%e ",
+			p[0].X, p[0].Y,
+			p[1].X, p[1].Y,
+			p[2].X, p[2].Y,
+		))
+	}
+	return path.String()
 }
 
 // BezierPoint is one Bezier curve control point
@@ -0,0 +1,362 @@
+package margaid
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/erkkah/margaid/xt"
+)
+
+func TestShortAreaLegend(t *testing.T) {
+	x := xt.X(t)
+
+	short := NewSeries(Titled("short"))
+	short.Add(MakeValue(1, 1))
+	long := NewSeries(Titled("long"))
+	long.Add(MakeValue(1, 1), MakeValue(2, 2))
+
+	m := New(100, 100)
+	m.Area(short)
+	m.StackedArea([]*Series{short, long})
+
+	x.Equal(len(m.plots), 3)
+	x.Equal(m.plots[1].name, "short")
+	x.Equal(m.plots[2].name, "long")
+}
+
+// rectExtents returns the value axis extents of the data rects
+// in a rendered diagram, as "start+length"
+func rectExtents(rendered string, horizontal bool) []string {
+	var extents []string
+	rects := regexp.MustCompile(`<rect height="([^"]+)" vector-effect="non-scaling-stroke" width="([^"]+)" x="([^"]+)" y="([^"]+)"/>`)
+	for _, match := range rects.FindAllStringSubmatch(rendered, -1) {
+		if horizontal {
+			extents = append(extents, match[3]+"+"+match[2])
+		} else {
+			extents = append(extents, match[4]+"+"+match[1])
+		}
+	}
+	return extents
+}
+
+func TestStackedBars(t *testing.T) {
+	x := xt.X(t)
+
+	for _, horizontal := range []bool{false, true} {
+		value := func(v float64) Value {
+			if horizontal {
+				return MakeValue(v, 1)
+			}
+			return MakeValue(1, v)
+		}
+		first := NewSeries()
+		first.Add(value(2))
+		second := NewSeries()
+		second.Add(value(-3))
+		third := NewSeries()
+		third.Add(value(1))
+
+		m := New(100, 100, WithInset(0), WithRange(XAxis, -5, 5), WithRange(YAxis, -5, 5))
+		using := []Using{UsingStacked()}
+		if horizontal {
+			using = append(using, UsingHorizontal())
+		}
+		m.Bar([]*Series{first, second, third}, using...)
+
+		// Positive values stack upwards from zero at 50,
+		// and negative values downwards
+		x.Equal(fmt.Sprint(rectExtents(render(m), horizontal)), "[50+20 20+30 70+10]", horizontal)
+	}
+}
+
+func TestStackedAreaBaseline(t *testing.T) {
+	x := xt.X(t)
+
+	first := NewSeries()
+	first.Add(MakeValue(0, 0), MakeValue(10, 0))
+	second := NewSeries()
+	second.Add(MakeValue(0, 5), MakeValue(10, 5))
+
+	m := New(100, 100, WithInset(0), WithRange(XAxis, 0, 10), WithRange(YAxis, -10, 10))
+	m.StackedArea([]*Series{first, second})
+	rendered := render(m)
+	// The first area is empty, and the second spans 0 to 5
+	x.Assert(strings.Contains(rendered, `d="M0,50 L100,50 L100,50 L0,50 "`), rendered)
+	x.Assert(strings.Contains(rendered, `d="M0,75 L100,75 L100,50 L0,50 "`), rendered)
+
+	m = New(100, 100, WithInset(0), WithRange(XAxis, 0, 10), WithRange(YAxis, -10, 10))
+	m.StackedArea([]*Series{first, second}, UsingBaseline(5))
+	rendered = render(m)
+	// The first area is filled down to the baseline
+	x.Assert(strings.Contains(rendered, `d="M0,50 L100,50 L100,75 L0,75 "`), rendered)
+	x.Assert(strings.Contains(rendered, `d="M0,75 L100,75 L100,50 L0,50 "`), rendered)
+}
+
+func TestCandlestickNeedsOHLC(t *testing.T) {
+	x := xt.X(t)
+
+	plain := NewSeries()
+	plain.Add(MakeValue(1, 2))
+	m := New(100, 100)
+	m.Candlestick(plain)
+	x.Assert(strings.Contains(render(m), "Candlestick needs OHLC values"))
+
+	ohlc := NewSeries()
+	ohlc.Add(MakeOHLCValue(1, 2, 4, 1, 3))
+	m = New(100, 100)
+	m.Candlestick(ohlc)
+	x.False(strings.Contains(render(m), "Candlestick needs OHLC values"))
+}
+
+type point = struct{ X, Y float64 }
+
+func TestStepPoints(t *testing.T) {
+	x := xt.X(t)
+
+	points := []point{{0, 0}, {2, 1}, {4, 3}}
+
+	for _, c := range []struct {
+		interpolation Interpolation
+		expected      []point
+	}{
+		{StepBefore, []point{{0, 0}, {0, 1}, {2, 1}, {2, 3}, {4, 3}}},
+		{StepAfter, []point{{0, 0}, {2, 0}, {2, 1}, {4, 1}, {4, 3}}},
+		{StepMid, []point{{0, 0}, {1, 0}, {1, 1}, {2, 1}, {3, 1}, {3, 3}, {4, 3}}},
+	} {
+		x.Equal(fmt.Sprint(stepPoints(points, c.interpolation)), fmt.Sprint(c.expected), c.interpolation)
+	}
+
+	x.Equal(len(stepPoints(nil, StepMid)), 0)
+	x.Equal(len(stepPoints(points[:1], StepMid)), 1)
+}
+
+func TestStepClosingPoints(t *testing.T) {
+	x := xt.X(t)
+
+	reverse := func(points []point) []point {
+		var reversed []point
+		for i := len(points) - 1; i >= 0; i-- {
+			reversed = append(reversed, points[i])
+		}
+		return reversed
+	}
+
+	// The closing points of a step area trace the same steps
+	// as the lower curve drawn forwards, but backwards
+	lower := []point{{0, 1}, {2, 0}, {4, 2}}
+	for _, interpolation := range []Interpolation{StepBefore, StepAfter, StepMid} {
+		forwards := stepPoints(lower, interpolation)
+		closing := stepClosingPoints(reverse(lower), interpolation)
+		x.Equal(fmt.Sprint(closing), fmt.Sprint(reverse(forwards)), interpolation)
+	}
+
+	x.Equal(fmt.Sprint(stepClosingPoints([]point{{4, 2}, {2, 0}}, StepBefore)), "[{4 2} {2 2} {2 0}]")
+}
+
+func TestPieSkipsEmptySeries(t *testing.T) {
+	x := xt.X(t)
+
+	a := NewSeries(Titled("a"))
+	a.Add(MakeValue(1, 1))
+	empty := NewSeries(Titled("empty"))
+	negative := NewSeries(Titled("negative"))
+	negative.Add(MakeValue(1, -1))
+	b := NewSeries(Titled("b"))
+	b.Add(MakeValue(1, 3))
+
+	m := New(100, 100)
+	m.Pie([]*Series{a, empty, negative, b})
+
+	x.Equal(len(m.plots), 2)
+	x.Equal(m.plots[0].name, "a")
+	x.Equal(m.plots[1].name, "b")
+}
+
+func TestCandlestickColors(t *testing.T) {
+	x := xt.X(t)
+
+	rising := NewSeries()
+	rising.Add(MakeOHLCValue(1, 2, 4, 1, 3))
+	falling := NewSeries()
+	falling.Add(MakeOHLCValue(1, 3, 4, 1, 2))
+
+	for _, reversed := range []bool{false, true} {
+		options := []Option{WithRange(YAxis, 0, 5)}
+		if reversed {
+			options = append(options, WithReversed(YAxis))
+		}
+
+		m := New(100, 100, options...)
+		m.Candlestick(rising, UsingRiseFallColors("green", "red"))
+		rendered := render(m)
+		x.Assert(strings.Contains(rendered, `fill="green"`), reversed)
+		x.False(strings.Contains(rendered, `fill="red"`), reversed)
+
+		m = New(100, 100, options...)
+		m.Candlestick(falling, UsingRiseFallColors("green", "red"))
+		rendered = render(m)
+		x.Assert(strings.Contains(rendered, `fill="red"`), reversed)
+		x.False(strings.Contains(rendered, `fill="green"`), reversed)
+	}
+}
+
+// circles returns the position and radius of the drawn circles
+// in a rendered diagram, as "cx,cy,r"
+func circles(rendered string) []string {
+	var found []string
+	pattern := regexp.MustCompile(`<circle cx="([^"]+)" cy="([^"]+)" r="([^"]+)"`)
+	for _, match := range pattern.FindAllStringSubmatch(rendered, -1) {
+		found = append(found, match[1]+","+match[2]+","+match[3])
+	}
+	return found
+}
+
+func TestScatter(t *testing.T) {
+	x := xt.X(t)
+
+	series := NewSeries(Titled("scatter"))
+	series.Add(MakeValue(20, 20), MakeValue(50, 60), MakeValue(80, 40))
+
+	m := New(100, 100, WithInset(0), WithRange(XAxis, 0, 100), WithRange(YAxis, 0, 100))
+	m.Scatter(series)
+	m.Legend(RightTop)
+	rendered := render(m)
+
+	// Filled circle markers, not connected by lines
+	x.False(strings.Contains(rendered, "<path"), rendered)
+	x.Equal(fmt.Sprint(circles(rendered)), "[20,20,4 50,60,4 80,40,4 122,12,6]")
+	x.Assert(strings.Contains(rendered, `fill="`+m.getPlotColor(0)+`" stroke="none"`), rendered)
+
+	// The legend shows the marker instead of a box
+	x.Equal(len(rectExtents(rendered, false)), 0)
+
+	m = New(100, 100, WithInset(0), WithRange(XAxis, 0, 100), WithRange(YAxis, 0, 100))
+	m.Scatter(series, UsingMarker("square"))
+	m.Legend(RightTop)
+	rendered = render(m)
+	x.Equal(len(circles(rendered)), 0)
+	x.Equal(len(rectExtents(rendered, false)), 4)
+}
+
+func TestBubble(t *testing.T) {
+	x := xt.X(t)
+
+	series := NewSeries(Titled("bubbles"))
+	series.Add(
+		MakeTriple(20, 20, 16),
+		MakeTriple(50, 50, 4),
+		MakeTriple(80, 80, 1),
+		MakeTriple(10, 90, 0),
+		MakeTriple(90, 10, -2),
+	)
+
+	m := New(100, 100, WithInset(0), WithRange(XAxis, 0, 100), WithRange(YAxis, 0, 100))
+	m.Bubble(series, UsingBubbleSize(20))
+	m.Legend(RightTop)
+	rendered := render(m)
+
+	// Radius grows with the square root of z, and sizes <= 0 are left out.
+	// The legend entry is a circle marker.
+	x.Equal(fmt.Sprint(circles(rendered)), "[20,20,20 50,50,10 80,80,5 122,12,6]")
+	x.Equal(m.plots[0].marker, "filled-circle")
+	x.Equal(len(rectExtents(rendered, false)), 0)
+}
+
+func TestSizeLegend(t *testing.T) {
+	x := xt.X(t)
+
+	series := NewSeries()
+	series.Add(MakeTriple(20, 20, 16), MakeTriple(50, 50, 2))
+
+	m := New(100, 100, WithInset(0))
+	m.SizeLegend(series, UsingBubbleSize(20))
+	rendered := render(m)
+
+	// Nested circles for the largest size and its quarter and sixteenth,
+	// resting on the bottom of the plotting area
+	x.Equal(fmt.Sprint(circles(rendered)), "[128,80,20 128,90,10 128,95,5]")
+	x.Assert(strings.Contains(rendered, `x="152" y="60">16</text>`), rendered)
+	x.Assert(strings.Contains(rendered, `x="152" y="80">4</text>`), rendered)
+	x.Assert(strings.Contains(rendered, `x="152" y="90">1</text>`), rendered)
+
+	m = New(100, 100)
+	m.SizeLegend(NewSeries(), UsingBubbleSize(20))
+	x.Equal(len(circles(render(m))), 0)
+}
+
+func TestSmallestGap(t *testing.T) {
+	x := xt.X(t)
+
+	x.Equal(smallestGap([]float64{10, 0, 10, 4, 0}), 4.0)
+	x.Equal(smallestGap([]float64{3, 3}), 1.0)
+	x.Equal(smallestGap(nil), 1.0)
+}
+
+// levelColormap names each color by its colormap level
+func levelColormap(level float64) string {
+	return fmt.Sprintf("level-%v", level)
+}
+
+// levels returns the colormap levels used in a rendered diagram, in order
+func levels(rendered string) []string {
+	var found []string
+	for _, match := range regexp.MustCompile(`fill="level-([^"]+)"`).FindAllStringSubmatch(rendered, -1) {
+		found = append(found, match[1])
+	}
+	return found
+}
+
+func TestHeatmap(t *testing.T) {
+	x := xt.X(t)
+
+	series := NewSeries()
+	series.Add(
+		MakeTriple(0, 0, 1),
+		MakeTriple(10, 0, 2),
+		MakeTriple(0, 5, 3),
+		MakeTriple(10, 5, 5),
+	)
+
+	m := New(100, 100, WithInset(0), WithRange(XAxis, -5, 45), WithRange(YAxis, -2.5, 47.5))
+	m.Heatmap(series, UsingColormap(levelColormap))
+	rendered := render(m)
+
+	// Cells are as large as the smallest gaps, 10 by 5, centered at each value
+	x.Equal(fmt.Sprint(rectExtents(rendered, true)), "[0+20 20+20 0+20 20+20]")
+	x.Equal(fmt.Sprint(rectExtents(rendered, false)), "[0+10 0+10 10+10 10+10]")
+	// and colored by z relative to the z range
+	x.Equal(fmt.Sprint(levels(rendered)), "[0 0.25 0.5 1]")
+
+	flat := NewSeries()
+	flat.Add(MakeTriple(0, 0, 7), MakeTriple(1, 0, 7))
+	m = New(100, 100)
+	m.Heatmap(flat, UsingColormap(levelColormap))
+	rendered = render(m)
+	// A flat z range puts all cells at the middle of the colormap
+	x.Equal(fmt.Sprint(levels(rendered)), "[0.5]")
+	x.Equal(len(rectExtents(rendered, false)), 2)
+}
+
+func TestColorBar(t *testing.T) {
+	x := xt.X(t)
+
+	series := NewSeries()
+	series.Add(MakeTriple(0, 0, 1), MakeTriple(1, 0, 5))
+
+	m := New(100, 100, WithInset(0))
+	m.ColorBar(series, UsingColormap(levelColormap))
+	rendered := render(m)
+
+	colors := levels(rendered)
+	x.Equal(len(colors), 32)
+	x.Equal(colors[0], "0.015625")
+	x.Equal(colors[31], "0.984375")
+
+	// Labels span the z range, from the bottom to the top of the bar
+	for i, label := range []string{"1", "2", "3", "4", "5"} {
+		position := fmt.Sprintf(`x="124" y="%v">%s</text>`, 100-25*i, label)
+		x.Assert(strings.Contains(rendered, position), position, rendered)
+	}
+}
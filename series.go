@@ -3,6 +3,7 @@ package margaid
 import (
 	"container/list"
 	"math"
+	"sort"
 	"time"
 )
 
@@ -13,12 +14,15 @@ type Series struct {
 	maxX   float64
 	minY   float64
 	maxY   float64
+	minZ   float64
+	maxZ   float64
 
 	title string
 
 	capper     Capper
 	aggregator Aggregator
 	interval   time.Duration
+	location   *time.Location
 	buffer     []Value
 	at         time.Time
 }
@@ -68,6 +72,18 @@ func (s *Series) MaxY() float64 {
 	return s.maxY
 }
 
+// MinZ returns the series smallest z value, or 0.0 if
+// the series is empty
+func (s *Series) MinZ() float64 {
+	return s.minZ
+}
+
+// MaxZ returns the series largest z value, or 0.0 if
+// the series is empty
+func (s *Series) MaxZ() float64 {
+	return s.maxZ
+}
+
 // SeriesIterator helps iterating series values
 type SeriesIterator struct {
 	list    *list.List
@@ -101,9 +117,31 @@ func (s *Series) Values() SeriesIterator {
 // Value is the type of each series element.
 // The X part represents a position on the X axis, which could
 // be time or a regular value.
+// The optional bounds represent a range around the value, such as
+// an error margin or the spread of aggregated values.
+// Bounds are referenced by pointer, so copies of a Value share its bounds.
+// Use WithXBounds and WithYBounds to get a copy with bounds of its own.
+//
+// Create values using MakeValue and friends, or keyed struct literals,
+// since fields may be added in later versions.
 type Value struct {
 	X float64
 	Y float64
+
+	XBounds *Bounds
+	YBounds *Bounds
+
+	// Open is the opening value of OHLC values, see MakeOHLCValue
+	Open float64
+
+	// Z is an optional third dimension, see MakeTriple
+	Z float64
+}
+
+// Bounds is the range [Low, High] around a value
+type Bounds struct {
+	Low  float64
+	High float64
 }
 
 // MakeValue creates a Value from x and y values.
@@ -111,6 +149,49 @@ func MakeValue(x float64, y float64) Value {
 	return Value{X: x, Y: y}
 }
 
+// MakeTriple creates a Value from x, y and z values, where z is a third
+// dimension such as a heatmap cell value or a bubble size.
+func MakeTriple(x, y, z float64) Value {
+	v := MakeValue(x, y)
+	v.Z = z
+	return v
+}
+
+// MakeOHLCValue creates an open-high-low-close value at x.
+// Y is set to the closing value, and the Y bounds to [low, high].
+func MakeOHLCValue(x float64, open, high, low, close float64) Value {
+	v := MakeValue(x, close).WithYBounds(low, high)
+	v.Open = open
+	return v
+}
+
+// WithXBounds returns a copy of the value with x bounds [low, high]
+func (v Value) WithXBounds(low, high float64) Value {
+	v.XBounds = &Bounds{Low: low, High: high}
+	return v
+}
+
+// WithYBounds returns a copy of the value with y bounds [low, high]
+func (v Value) WithYBounds(low, high float64) Value {
+	v.YBounds = &Bounds{Low: low, High: high}
+	return v
+}
+
+// extent returns the smallest and largest x and y of a value,
+// including its bounds
+func (v Value) extent() (minX, maxX, minY, maxY float64) {
+	minX, maxX, minY, maxY = v.X, v.X, v.Y, v.Y
+	if v.XBounds != nil {
+		minX = math.Min(minX, v.XBounds.Low)
+		maxX = math.Max(maxX, v.XBounds.High)
+	}
+	if v.YBounds != nil {
+		minY = math.Min(minY, v.YBounds.Low)
+		maxY = math.Max(maxY, v.YBounds.High)
+	}
+	return
+}
+
 // Add appends one or more values, optionally
 // peforming aggregation.
 // If the series is capped, capping will be applied
@@ -123,17 +204,17 @@ func (s *Series) Add(values ...Value) {
 	if s.aggregator != nil {
 
 		if s.values.Len() == 0 && len(s.buffer) == 0 {
-			s.at = TimeFromSeconds(values[0].X).Truncate(s.interval)
+			s.at = s.truncate(TimeFromSeconds(values[0].X))
 		}
 
 		var aggregated []Value
 
 		for _, v := range values {
 			at := TimeFromSeconds(v.X)
-			if s.at.Add(s.interval).Before(at) {
+			if s.intervalEnd(s.at).Before(at) {
 				agg := s.aggregator(s.buffer, s.at)
 				aggregated = append(aggregated, agg)
-				s.at = at.Truncate(s.interval)
+				s.at = s.truncate(at)
 				s.buffer = append(s.buffer[0:0], v)
 			} else {
 				s.buffer = append(s.buffer, v)
@@ -144,16 +225,21 @@ func (s *Series) Add(values ...Value) {
 	}
 
 	for _, v := range values {
+		minX, maxX, minY, maxY := v.extent()
 		if s.values.Len() == 0 {
-			s.minX = v.X
-			s.maxX = v.X
-			s.minY = v.Y
-			s.maxY = v.Y
+			s.minX = minX
+			s.maxX = maxX
+			s.minY = minY
+			s.maxY = maxY
+			s.minZ = v.Z
+			s.maxZ = v.Z
 		} else {
-			s.minX = math.Min(s.minX, v.X)
-			s.maxX = math.Max(s.maxX, v.X)
-			s.minY = math.Min(s.minY, v.Y)
-			s.maxY = math.Max(s.maxY, v.Y)
+			s.minX = math.Min(s.minX, minX)
+			s.maxX = math.Max(s.maxX, maxX)
+			s.minY = math.Min(s.minY, minY)
+			s.maxY = math.Max(s.maxY, maxY)
+			s.minZ = math.Min(s.minZ, v.Z)
+			s.maxZ = math.Max(s.maxZ, v.Z)
 		}
 		s.values.PushBack(v)
 		if s.capper != nil {
@@ -178,6 +264,18 @@ func (s *Series) Zip(xValues, yValues []float64) {
 	s.Add(zipped...)
 }
 
+// Matrix adds the cells of a matrix as z values, with the column
+// index as x and the row index as y.
+func (s *Series) Matrix(rows [][]float64) {
+	var cells []Value
+	for y, row := range rows {
+		for x, z := range row {
+			cells = append(cells, MakeTriple(float64(x), float64(y), z))
+		}
+	}
+	s.Add(cells...)
+}
+
 func (s *Series) updateMinMax() {
 	if s.values.Len() == 0 {
 		return
@@ -186,18 +284,105 @@ func (s *Series) updateMinMax() {
 	values := s.Values()
 	values.Next()
 	current := values.Get()
-	s.minX = current.X
-	s.maxX = current.X
-	s.minY = current.Y
-	s.maxY = current.Y
+	s.minX, s.maxX, s.minY, s.maxY = current.extent()
+	s.minZ = current.Z
+	s.maxZ = current.Z
 
 	for values.Next() {
 		current = values.Get()
-		s.minX = math.Min(s.minX, current.X)
-		s.maxX = math.Max(s.maxX, current.X)
-		s.minY = math.Min(s.minY, current.Y)
-		s.maxY = math.Max(s.maxY, current.Y)
+		minX, maxX, minY, maxY := current.extent()
+		s.minX = math.Min(s.minX, minX)
+		s.maxX = math.Max(s.maxX, maxX)
+		s.minY = math.Min(s.minY, minY)
+		s.maxY = math.Max(s.maxY, maxY)
+		s.minZ = math.Min(s.minZ, current.Z)
+		s.maxZ = math.Max(s.maxZ, current.Z)
+	}
+}
+
+// Quantile returns the q-quantile [0..1] of the series y values,
+// interpolating linearly between values. Quantile(0.5) is the median.
+// Returns 0.0 if the series is empty.
+func (s *Series) Quantile(q float64) float64 {
+	var values []float64
+	iterator := s.Values()
+	for iterator.Next() {
+		values = append(values, iterator.Get().Y)
+	}
+	sort.Float64s(values)
+	return quantile(values, q)
+}
+
+// quantile calculates the q-quantile of sorted values
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	q = math.Max(0, math.Min(1, q))
+	position := q * float64(len(sorted)-1)
+	below := math.Floor(position)
+	above := math.Ceil(position)
+	fraction := position - below
+	return sorted[int(below)]*(1-fraction) + sorted[int(above)]*fraction
+}
+
+// groupByX collects the y values of the series for each distinct x value.
+// The x values are returned in order of first appearance, and the y values
+// are sorted.
+func (s *Series) groupByX() (xValues []float64, groups map[float64][]float64) {
+	groups = map[float64][]float64{}
+	iterator := s.Values()
+	for iterator.Next() {
+		v := iterator.Get()
+		if _, found := groups[v.X]; !found {
+			xValues = append(xValues, v.X)
+		}
+		groups[v.X] = append(groups[v.X], v.Y)
 	}
+	for _, group := range groups {
+		sort.Float64s(group)
+	}
+	return
+}
+
+// stack calculates lower and upper values for stacking series on top of
+// each other in order, by summing y values at equal x positions.
+// Positive values stack upwards and negative values downwards from zero.
+// Horizontal stacking sums x values at equal y positions.
+func stack(series []*Series, horizontal bool) (lower, upper [][]Value) {
+	positive := map[float64]float64{}
+	negative := map[float64]float64{}
+
+	for _, s := range series {
+		var l, u []Value
+		values := s.Values()
+		for values.Next() {
+			v := values.Get()
+			position, value := v.X, v.Y
+			if horizontal {
+				position, value = v.Y, v.X
+			}
+
+			totals := positive
+			if value < 0 {
+				totals = negative
+			}
+			base := totals[position]
+			totals[position] = base + value
+
+			if horizontal {
+				l = append(l, MakeValue(base, position))
+				u = append(u, MakeValue(base+value, position))
+			} else {
+				l = append(l, MakeValue(position, base))
+				u = append(u, MakeValue(position, base+value))
+			}
+		}
+		lower = append(lower, l)
+		upper = append(upper, u)
+	}
+	return
 }
 
 // Capper is the capping function type
@@ -257,7 +442,44 @@ func Avg(values []Value, at time.Time) Value {
 		sum += v.Y
 	}
 	avg := sum / float64(len(values))
-	return Value{SecondsFromTime(at), avg}
+	return MakeValue(SecondsFromTime(at), avg)
+}
+
+// AvgRange calculates the Y average of a list of values,
+// reported as observed at the given time, with Y bounds
+// set to the smallest and largest Y value.
+func AvgRange(values []Value, at time.Time) Value {
+	if len(values) == 0 {
+		return Value{}
+	}
+
+	avg := Avg(values, at)
+	min := values[0].Y
+	max := values[0].Y
+	for _, v := range values[1:] {
+		min = math.Min(min, v.Y)
+		max = math.Max(max, v.Y)
+	}
+	return avg.WithYBounds(min, max)
+}
+
+// OHLC calculates the open, high, low and close Y values
+// of a list of values, reported as observed at the given time.
+// See MakeOHLCValue.
+func OHLC(values []Value, at time.Time) Value {
+	if len(values) == 0 {
+		return Value{}
+	}
+
+	open := values[0].Y
+	close := values[len(values)-1].Y
+	high := open
+	low := open
+	for _, v := range values[1:] {
+		high = math.Max(high, v.Y)
+		low = math.Min(low, v.Y)
+	}
+	return MakeOHLCValue(SecondsFromTime(at), open, high, low, close)
 }
 
 // Sum calculates the Y sum of a list of values,
@@ -267,7 +489,7 @@ func Sum(values []Value, at time.Time) Value {
 	for _, v := range values {
 		sum += v.Y
 	}
-	return Value{SecondsFromTime(at), sum}
+	return MakeValue(SecondsFromTime(at), sum)
 }
 
 // Delta calculates the Y difference between the first and
@@ -279,7 +501,7 @@ func Delta(values []Value, at time.Time) Value {
 
 	first := values[0].Y
 	last := values[len(values)-1].Y
-	return Value{SecondsFromTime(at), last - first}
+	return MakeValue(SecondsFromTime(at), last-first)
 }
 
 // AggregatedBy sets the series aggregator
@@ -290,6 +512,45 @@ func AggregatedBy(f Aggregator, interval time.Duration) SeriesOption {
 	}
 }
 
+// AggregatedIn sets the location used for aligning aggregation intervals
+// to wall clock time. Without a location, intervals are aligned to UTC.
+// Intervals of whole days start at midnight in the location.
+func AggregatedIn(location *time.Location) SeriesOption {
+	return func(s *Series) {
+		s.location = location
+	}
+}
+
+// truncate rounds a time down to the start of its aggregation interval
+func (s *Series) truncate(t time.Time) time.Time {
+	if s.location == nil {
+		return t.Truncate(s.interval)
+	}
+
+	const day = 24 * time.Hour
+	if s.interval >= day && s.interval%day == 0 {
+		year, month, date := t.In(s.location).Date()
+		days := time.Date(year, month, date, 0, 0, 0, 0, time.UTC).Unix() / int64(day/time.Second)
+		interval := int64(s.interval / day)
+		days -= ((days % interval) + interval) % interval
+		return time.Date(1970, time.January, 1+int(days), 0, 0, 0, 0, s.location)
+	}
+
+	_, offset := t.In(s.location).Zone()
+	shift := time.Duration(offset) * time.Second
+	return t.Add(shift).Truncate(s.interval).Add(-shift)
+}
+
+// intervalEnd returns the end of the aggregation interval starting at t
+func (s *Series) intervalEnd(t time.Time) time.Time {
+	const day = 24 * time.Hour
+	if s.location != nil && s.interval >= day && s.interval%day == 0 {
+		// Days are not always 24 hours long in locations using daylight saving time
+		return t.AddDate(0, 0, int(s.interval/day))
+	}
+	return t.Add(s.interval)
+}
+
 // Titled sets the series title
 func Titled(title string) SeriesOption {
 	return func(s *Series) {
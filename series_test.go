@@ -124,3 +124,174 @@ func TestAggregateDelta(t *testing.T) {
 
 	x.False(values.Next(), "Series should be empty")
 }
+
+func TestStack(t *testing.T) {
+	x := xt.X(t)
+
+	a := NewSeries()
+	a.Add(MakeValue(1, 10), MakeValue(2, 20))
+	b := NewSeries()
+	b.Add(MakeValue(1, 1), MakeValue(3, 3))
+
+	lower, upper := stack([]*Series{a, b}, false)
+
+	x.Equal(len(lower), 2)
+	x.Equal(lower[1][0].Y, 10.0)
+	x.Equal(upper[1][0].Y, 11.0)
+	x.Equal(lower[1][1].Y, 0.0)
+	x.Equal(upper[1][1].Y, 3.0)
+}
+
+func TestStackHorizontal(t *testing.T) {
+	x := xt.X(t)
+
+	a := NewSeries()
+	a.Add(MakeValue(10, 1), MakeValue(20, 2))
+	b := NewSeries()
+	b.Add(MakeValue(1, 1))
+
+	lower, upper := stack([]*Series{a, b}, true)
+
+	x.Equal(lower[1][0].X, 10.0)
+	x.Equal(upper[1][0].X, 11.0)
+	x.Equal(upper[1][0].Y, 1.0)
+}
+
+func TestStackNegative(t *testing.T) {
+	x := xt.X(t)
+
+	a := NewSeries()
+	a.Add(MakeValue(1, 2))
+	b := NewSeries()
+	b.Add(MakeValue(1, -3))
+	c := NewSeries()
+	c.Add(MakeValue(1, -1))
+
+	lower, upper := stack([]*Series{a, b, c}, false)
+
+	x.Equal(upper[0][0].Y, 2.0)
+	x.Equal(lower[1][0].Y, 0.0)
+	x.Equal(upper[1][0].Y, -3.0)
+	x.Equal(lower[2][0].Y, -3.0)
+	x.Equal(upper[2][0].Y, -4.0)
+}
+
+func TestAggregateAvgRange(t *testing.T) {
+	x := xt.X(t)
+
+	s := NewSeries(AggregatedBy(AvgRange, time.Second))
+
+	now := time.Now().Truncate(time.Second).Add(time.Millisecond * 50)
+	later := now.Add(time.Millisecond * 100)
+	tooLate := now.Add(time.Second)
+
+	s.Add(
+		MakeValue(SecondsFromTime(now), 10),
+		MakeValue(SecondsFromTime(later), 20),
+		MakeValue(SecondsFromTime(tooLate), 30),
+	)
+
+	values := s.Values()
+	x.True(values.Next())
+
+	v := values.Get()
+	x.Equal(v.Y, 15.0)
+	x.NotNil(v.YBounds)
+	x.Equal(v.YBounds.Low, 10.0)
+	x.Equal(v.YBounds.High, 20.0)
+}
+
+func TestMinMaxWithBounds(t *testing.T) {
+	x := xt.X(t)
+
+	s := NewSeries()
+	s.Add(MakeValue(0, 1).WithYBounds(0.5, 1.5))
+	s.Add(MakeValue(1, 2).WithXBounds(-1, 3))
+
+	x.Equal(s.MinY(), 0.5)
+	x.Equal(s.MaxY(), 2.0)
+	x.Equal(s.MinX(), -1.0)
+	x.Equal(s.MaxX(), 3.0)
+}
+
+func TestAggregateOHLC(t *testing.T) {
+	x := xt.X(t)
+
+	s := NewSeries(AggregatedBy(OHLC, time.Second))
+
+	now := time.Now().Truncate(time.Second).Add(time.Millisecond * 50)
+	tooLate := now.Add(time.Second)
+
+	s.Add(
+		MakeValue(SecondsFromTime(now), 10),
+		MakeValue(SecondsFromTime(now.Add(time.Millisecond*100)), 30),
+		MakeValue(SecondsFromTime(now.Add(time.Millisecond*200)), 5),
+		MakeValue(SecondsFromTime(now.Add(time.Millisecond*300)), 20),
+		MakeValue(SecondsFromTime(tooLate), 30),
+	)
+
+	values := s.Values()
+	x.True(values.Next())
+
+	v := values.Get()
+	x.Equal(v.Open, 10.0)
+	x.Equal(v.YBounds.High, 30.0)
+	x.Equal(v.YBounds.Low, 5.0)
+	x.Equal(v.Y, 20.0)
+	x.Equal(s.MaxY(), 30.0)
+}
+
+func TestQuantile(t *testing.T) {
+	x := xt.X(t)
+
+	s := NewSeries()
+	s.Add(MakeValue(0, 4), MakeValue(1, 1), MakeValue(2, 3), MakeValue(3, 2))
+
+	x.Equal(s.Quantile(0), 1.0)
+	x.Equal(s.Quantile(0.5), 2.5)
+	x.Equal(s.Quantile(1), 4.0)
+	x.Equal(NewSeries().Quantile(0.5), 0.0)
+}
+
+func TestMatrix(t *testing.T) {
+	x := xt.X(t)
+
+	s := NewSeries()
+	s.Matrix([][]float64{
+		{1, 2, 3},
+		{4, 5, 6},
+	})
+
+	x.Equal(s.Size(), 6)
+	x.Equal(s.MaxX(), 2.0)
+	x.Equal(s.MaxY(), 1.0)
+	x.Equal(s.MinZ(), 1.0)
+	x.Equal(s.MaxZ(), 6.0)
+}
+
+func TestAggregateInLocation(t *testing.T) {
+	x := xt.X(t)
+
+	location := time.FixedZone("UTC-5", -5*3600)
+	s := NewSeries(AggregatedBy(Sum, 24*time.Hour), AggregatedIn(location))
+
+	// Both values are on September 4 in the location, but on different UTC days
+	morning := time.Date(2020, time.September, 4, 8, 0, 0, 0, location)
+	evening := time.Date(2020, time.September, 4, 22, 0, 0, 0, location)
+	nextDay := time.Date(2020, time.September, 5, 22, 0, 0, 0, location)
+
+	s.Add(
+		MakeValue(SecondsFromTime(morning), 10),
+		MakeValue(SecondsFromTime(evening), 20),
+		MakeValue(SecondsFromTime(nextDay), 30),
+	)
+
+	values := s.Values()
+	x.True(values.Next())
+
+	v := values.Get()
+	x.Equal(v.Y, 30.0)
+	x.Equal(v.X, SecondsFromTime(time.Date(2020, time.September, 4, 0, 0, 0, 0, location)))
+
+	x.False(values.Next(), "Series should be empty")
+}
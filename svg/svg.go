@@ -2,10 +2,12 @@ package svg
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 
 	br "github.com/erkkah/margaid/brackets"
+	"github.com/erkkah/margaid/metrics"
 )
 
 // SVG builds SVG format images using a small subset of the standard
@@ -259,8 +261,15 @@ func (svg *SVG) Polyline(points ...struct{ X, Y float64 }) *SVG {
 	return svg.Path(path.String())
 }
 
-// Rect adds a rect defined by x, y, width and height
+// Rect adds a rect defined by x, y, width and height.
+// Negative width or height extends the rect to the left or downwards.
 func (svg *SVG) Rect(x, y, width, height float64) *SVG {
+	if width < 0 {
+		x, width = x+width, -width
+	}
+	if height < 0 {
+		y, height = y+height, -height
+	}
 	svg.updateStyle()
 	svg.brackets.Add("rect", br.Attributes{
 		"x":             ftos(x),
@@ -272,6 +281,59 @@ func (svg *SVG) Rect(x, y, width, height float64) *SVG {
 	return svg
 }
 
+// Circle adds a circle centered at x, y with radius r
+func (svg *SVG) Circle(x, y, r float64) *SVG {
+	svg.updateStyle()
+	svg.brackets.Add("circle", br.Attributes{
+		"cx":            ftos(x),
+		"cy":            ftos(y),
+		"r":             ftos(r),
+		"vector-effect": "non-scaling-stroke",
+	})
+	return svg
+}
+
+// Sector adds a circle sector centered at cx, cy, spanning the start and
+// end angles in degrees, clockwise from the positive x axis.
+// A non-zero inner radius cuts out the center, making an annular sector.
+func (svg *SVG) Sector(cx, cy, radius, innerRadius, startAngle, endAngle float64) *SVG {
+	point := func(r, angle float64) string {
+		radians := angle * math.Pi / 180
+		return fmt.Sprintf("%s,%s", ftos(cx+r*math.Cos(radians)), ftos(cy+r*math.Sin(radians)))
+	}
+
+	var path strings.Builder
+
+	if endAngle-startAngle >= 360 {
+		// Full circles are drawn as two half circle arcs
+		path.WriteString(fmt.Sprintf("M%s ", point(radius, startAngle)))
+		path.WriteString(fmt.Sprintf("A%s,%s 0 1 1 %s ", ftos(radius), ftos(radius), point(radius, startAngle+180)))
+		path.WriteString(fmt.Sprintf("A%s,%s 0 1 1 %s Z ", ftos(radius), ftos(radius), point(radius, startAngle)))
+		if innerRadius > 0 {
+			path.WriteString(fmt.Sprintf("M%s ", point(innerRadius, startAngle)))
+			path.WriteString(fmt.Sprintf("A%s,%s 0 1 0 %s ", ftos(innerRadius), ftos(innerRadius), point(innerRadius, startAngle+180)))
+			path.WriteString(fmt.Sprintf("A%s,%s 0 1 0 %s Z ", ftos(innerRadius), ftos(innerRadius), point(innerRadius, startAngle)))
+		}
+		return svg.Path(path.String())
+	}
+
+	largeArc := 0
+	if endAngle-startAngle > 180 {
+		largeArc = 1
+	}
+
+	path.WriteString(fmt.Sprintf("M%s ", point(radius, startAngle)))
+	path.WriteString(fmt.Sprintf("A%s,%s 0 %d 1 %s ", ftos(radius), ftos(radius), largeArc, point(radius, endAngle)))
+	if innerRadius > 0 {
+		path.WriteString(fmt.Sprintf("L%s ", point(innerRadius, endAngle)))
+		path.WriteString(fmt.Sprintf("A%s,%s 0 %d 0 %s ", ftos(innerRadius), ftos(innerRadius), largeArc, point(innerRadius, startAngle)))
+	} else {
+		path.WriteString(fmt.Sprintf("L%s,%s ", ftos(cx), ftos(cy)))
+	}
+	path.WriteString("Z ")
+	return svg.Path(path.String())
+}
+
 // Text draws text at x, y
 func (svg *SVG) Text(x, y float64, txt string) *SVG {
 	svg.updateStyle()
@@ -364,8 +426,48 @@ func (svg *SVG) StrokeWidth(width string) *SVG {
 	return svg
 }
 
+// Dash sets the current stroke dash pattern as a valid SVG
+// stroke-dasharray attribute string, like "4 2".
+// Setting the pattern to the empty string draws solid strokes.
+func (svg *SVG) Dash(pattern string) *SVG {
+	svg.setAttribute("stroke-dasharray", pattern)
+	return svg
+}
+
+// ClipRect defines a rectangular clip path with the given id,
+// for use with Clip. The rect is given in the user space of the
+// clipped elements, so any current transform applies to it.
+func (svg *SVG) ClipRect(id string, x, y, width, height float64) *SVG {
+	svg.brackets.Open("defs").
+		Open("clipPath", br.Attributes{
+			"id": id,
+		}).
+		Add("rect", br.Attributes{
+			"x":      ftos(x),
+			"y":      ftos(y),
+			"width":  ftos(width),
+			"height": ftos(height),
+		}).
+		Close().
+		Close()
+	return svg
+}
+
+// Clip clips all following elements to the clip path with the given id,
+// as defined by ClipRect.
+// Setting the id to the empty string clears clipping.
+func (svg *SVG) Clip(id string) *SVG {
+	reference := ""
+	if id != "" {
+		reference = fmt.Sprintf("url(#%s)", id)
+	}
+	svg.setAttribute("clip-path", reference)
+	return svg
+}
+
 // Marker adds start, mid and end markers to all following strokes.
-// The specified marker has to be one of "circle" and "square".
+// The specified marker has to be one of "circle", "filled-circle",
+// "square" and "filled-square".
 // Setting the marker to the empty string clears the marker.
 func (svg *SVG) Marker(marker string) *SVG {
 	reference := ""
@@ -450,19 +552,26 @@ func ftos(value float64) string {
 // EncodeText applies proper xml escaping and svg line breaking
 // at each newline in the raw text and returns a section ready
 // for inclusion in a <text> element.
-// NOTE: Line breaking is kind of hacky, since we cannot actually
-// measure text in SVG, and assume that all characters are 1ex wide.
+// Lines are aligned assuming a sans-serif font, see EncodeTextFor.
 func EncodeText(raw string, alignment HAlignment) string {
+	return EncodeTextFor(raw, alignment, "sans-serif")
+}
+
+// EncodeTextFor works like EncodeText, aligning lines using the
+// font metrics best matching the given font family.
+// NOTE: Line breaking is kind of hacky, since we cannot actually
+// measure text in SVG, and rely on estimated character widths.
+func EncodeTextFor(raw string, alignment HAlignment, family string) string {
 	chunks := strings.Split(raw, "\n")
 	if len(chunks) > 1 {
 		var lines strings.Builder
+		face := metrics.ForFamily(family)
 
 		lines.WriteString(br.XMLEscape(chunks[0]))
-		previousLength := float64(len(chunks[0]))
+		previousLength := face.EmWidth(chunks[0])
 
 		for _, chunk := range chunks[1:] {
-			chunk = br.XMLEscape(chunk)
-			currentLength := float64(len(chunk))
+			currentLength := face.EmWidth(chunk)
 
 			carriageReturn := 0.0
 			switch alignment {
@@ -474,8 +583,8 @@ func EncodeText(raw string, alignment HAlignment) string {
 				carriageReturn = currentLength
 			}
 
-			lines.WriteString(fmt.Sprintf(`<tspan dx="-%fex" dy="1em">%s</tspan>`, carriageReturn, chunk))
-			previousLength = float64(len(chunk))
+			lines.WriteString(fmt.Sprintf(`<tspan dx="-%fem" dy="1em">%s</tspan>`, carriageReturn, br.XMLEscape(chunk)))
+			previousLength = currentLength
 		}
 		return lines.String()
 	}
@@ -0,0 +1,55 @@
+package svg
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/erkkah/margaid/xt"
+)
+
+// arcFlags returns the large arc and sweep flags of each arc
+// in the rendered path data
+func arcFlags(rendered string) []string {
+	var flags []string
+	for _, match := range regexp.MustCompile(`A\S+,\S+ 0 (\d) (\d) `).FindAllStringSubmatch(rendered, -1) {
+		flags = append(flags, match[1]+match[2])
+	}
+	return flags
+}
+
+func TestSectorLargeArc(t *testing.T) {
+	x := xt.X(t)
+
+	small := New(100, 100, "white").Sector(50, 50, 10, 0, 0, 180).Render()
+	x.Equal(strings.Join(arcFlags(small), " "), "01")
+
+	large := New(100, 100, "white").Sector(50, 50, 10, 0, 0, 181).Render()
+	x.Equal(strings.Join(arcFlags(large), " "), "11")
+	x.Assert(strings.Contains(large, "L50,50 Z"), large)
+}
+
+func TestSectorFullCircle(t *testing.T) {
+	x := xt.X(t)
+
+	// A single arc can not start and end at the same point
+	circle := New(100, 100, "white").Sector(50, 50, 10, 0, 0, 360).Render()
+	x.Equal(strings.Join(arcFlags(circle), " "), "11 11")
+	x.Assert(strings.Contains(circle, "M60,50 A10,10 0 1 1 40,"), circle)
+	x.False(strings.Contains(circle, "L"))
+
+	ring := New(100, 100, "white").Sector(50, 50, 10, 5, 0, 360).Render()
+	x.Equal(strings.Join(arcFlags(ring), " "), "11 11 10 10")
+}
+
+func TestSectorInnerRadius(t *testing.T) {
+	x := xt.X(t)
+
+	// The inner arc runs back against the outer one
+	sector := New(100, 100, "white").Sector(50, 50, 10, 5, 0, 90).Render()
+	x.Equal(strings.Join(arcFlags(sector), " "), "01 00")
+	x.Assert(strings.Contains(sector, "A5,5 0 0 0 55,50 Z"), sector)
+
+	large := New(100, 100, "white").Sector(50, 50, 10, 5, 0, 270).Render()
+	x.Equal(strings.Join(arcFlags(large), " "), "11 10")
+}
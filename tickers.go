@@ -4,19 +4,57 @@ import (
 	"math"
 	"strconv"
 	"time"
-
-	"github.com/erkkah/margaid/svg"
 )
 
-// Ticker provides tick marks and labels for axes
+// Ticker provides tick marks and labels for axes.
+// Labels are plain text, possibly spanning multiple lines.
+// Custom tickers are created from a TickProvider using CustomTicker.
 type Ticker interface {
 	label(value float64) string
 	start(axis Axis, series *Series, steps int) float64
 	next(previous float64) (next float64, hasMore bool)
 }
 
+// TickProvider is implemented by custom tick strategies.
+// Use CustomTicker to turn a TickProvider into a Ticker for Axis.
+type TickProvider interface {
+	// Label returns the label text for the tick at value.
+	Label(value float64) string
+	// Start returns the first tick value of an axis ranging from min to max,
+	// aiming for at most steps ticks in total.
+	Start(axis Axis, series *Series, min, max float64, steps int) float64
+	// Next returns the tick value following previous,
+	// and whether there are any more ticks.
+	Next(previous float64) (next float64, hasMore bool)
+}
+
+// CustomTicker returns a Ticker that places tick marks and labels
+// as provided by a user supplied TickProvider.
+func (m *Margaid) CustomTicker(provider TickProvider) Ticker {
+	return &customTicker{m, provider}
+}
+
+type customTicker struct {
+	m        *Margaid
+	provider TickProvider
+}
+
+func (t *customTicker) label(value float64) string {
+	return t.provider.Label(value)
+}
+
+func (t *customTicker) start(axis Axis, series *Series, steps int) float64 {
+	minmax := t.m.ranges[axis]
+	return t.provider.Start(axis, series, minmax.min, minmax.max, steps)
+}
+
+func (t *customTicker) next(previous float64) (float64, bool) {
+	return t.provider.Next(previous)
+}
+
 // TimeTicker returns time valued tick labels in the specified time format.
 // TimeTicker assumes that time is linear.
+// Times are presented in the diagram location, see WithLocation.
 func (m *Margaid) TimeTicker(format string) Ticker {
 	return &timeTicker{m, format, 1}
 }
@@ -28,8 +66,7 @@ type timeTicker struct {
 }
 
 func (t *timeTicker) label(value float64) string {
-	formatted := TimeFromSeconds(value).Format(t.format)
-	return svg.EncodeText(formatted, svg.HAlignMiddle)
+	return TimeFromSeconds(value).In(t.m.location).Format(t.format)
 }
 
 func (t *timeTicker) start(axis Axis, _ *Series, steps int) float64 {
@@ -56,6 +93,193 @@ func (t *timeTicker) next(previous float64) (float64, bool) {
 	return previous + t.step, true
 }
 
+// CalendarTicker returns time valued tick labels, placed at human friendly
+// intervals aligned to calendar boundaries in the specified location.
+// The label format is picked from the tick interval, showing dates
+// only where the day changes for intervals shorter than a day.
+// A nil location uses the diagram location, see WithLocation.
+func (m *Margaid) CalendarTicker(location *time.Location) Ticker {
+	if location == nil {
+		location = m.location
+	}
+	return &calendarTicker{m: m, location: location}
+}
+
+type calendarUnit int
+
+const (
+	secondUnit calendarUnit = iota
+	minuteUnit
+	hourUnit
+	dayUnit
+	weekUnit
+	monthUnit
+	yearUnit
+)
+
+// calendarStep is a tick interval of count calendar units
+type calendarStep struct {
+	unit  calendarUnit
+	count int
+}
+
+// calendarSteps lists the supported tick intervals, from smallest to largest
+var calendarSteps = []calendarStep{
+	{secondUnit, 1}, {secondUnit, 2}, {secondUnit, 5}, {secondUnit, 10}, {secondUnit, 15}, {secondUnit, 30},
+	{minuteUnit, 1}, {minuteUnit, 2}, {minuteUnit, 5}, {minuteUnit, 10}, {minuteUnit, 15}, {minuteUnit, 30},
+	{hourUnit, 1}, {hourUnit, 2}, {hourUnit, 3}, {hourUnit, 6}, {hourUnit, 12},
+	{dayUnit, 1}, {dayUnit, 2},
+	{weekUnit, 1}, {weekUnit, 2},
+	{monthUnit, 1}, {monthUnit, 2}, {monthUnit, 3}, {monthUnit, 6},
+	{yearUnit, 1}, {yearUnit, 2}, {yearUnit, 5}, {yearUnit, 10}, {yearUnit, 20}, {yearUnit, 50}, {yearUnit, 100},
+}
+
+// approximate length in seconds of each calendar unit
+var calendarUnitSeconds = map[calendarUnit]float64{
+	secondUnit: 1,
+	minuteUnit: 60,
+	hourUnit:   3600,
+	dayUnit:    86400,
+	weekUnit:   7 * 86400,
+	monthUnit:  30.44 * 86400,
+	yearUnit:   365.25 * 86400,
+}
+
+type calendarTicker struct {
+	m        *Margaid
+	location *time.Location
+	step     calendarStep
+	first    float64
+}
+
+func (t *calendarTicker) label(value float64) string {
+	tick := TimeFromSeconds(value).In(t.location)
+	hour, minute, second := tick.Clock()
+	midnight := hour == 0 && minute == 0 && second == 0
+
+	// Ticks are aligned to calendar boundaries, so the first tick of
+	// each day is at midnight, and the first tick of each year is on
+	// the first day of the year, or the first week of the year for weekly ticks.
+	firstDay := tick.YearDay() == 1
+	if t.step.unit == weekUnit {
+		firstDay = tick.YearDay() <= 7*t.step.count
+	}
+	newYear := value == t.first || firstDay && midnight
+	newDay := value == t.first || midnight
+
+	var formatted string
+	switch t.step.unit {
+	case yearUnit:
+		formatted = tick.Format("2006")
+	case monthUnit:
+		formatted = tick.Format("Jan")
+		if newYear {
+			formatted = tick.Format("Jan\n2006")
+		}
+	case weekUnit, dayUnit:
+		formatted = tick.Format("Jan 2")
+		if newYear {
+			formatted = tick.Format("Jan 2\n2006")
+		}
+	default:
+		format := "15:04"
+		if t.step.unit == secondUnit {
+			format = "15:04:05"
+		}
+		formatted = tick.Format(format)
+		if newDay {
+			formatted += tick.Format("\nJan 2")
+		}
+	}
+	return formatted
+}
+
+func (t *calendarTicker) start(axis Axis, _ *Series, steps int) float64 {
+	minmax := t.m.ranges[axis]
+	scaleRange := minmax.max - minmax.min
+
+	t.step = calendarSteps[len(calendarSteps)-1]
+	for _, step := range calendarSteps {
+		length := calendarUnitSeconds[step.unit] * float64(step.count)
+		if scaleRange/length <= float64(steps) {
+			t.step = step
+			break
+		}
+	}
+
+	start := t.align(TimeFromSeconds(minmax.min))
+	if SecondsFromTime(start) < minmax.min {
+		start = t.advance(start)
+	}
+	t.first = SecondsFromTime(start)
+	return t.first
+}
+
+func (t *calendarTicker) next(previous float64) (float64, bool) {
+	return SecondsFromTime(t.advance(TimeFromSeconds(previous))), true
+}
+
+// align truncates a time to the closest preceding calendar step boundary
+func (t *calendarTicker) align(tm time.Time) time.Time {
+	tm = tm.In(t.location)
+	year, month, day := tm.Date()
+	hour, minute, second := tm.Clock()
+	count := t.step.count
+
+	switch t.step.unit {
+	case secondUnit:
+		return time.Date(year, month, day, hour, minute, second/count*count, 0, t.location)
+	case minuteUnit:
+		return time.Date(year, month, day, hour, minute/count*count, 0, 0, t.location)
+	case hourUnit:
+		return time.Date(year, month, day, hour/count*count, 0, 0, 0, t.location)
+	case dayUnit:
+		return time.Date(year, month, (day-1)/count*count+1, 0, 0, 0, 0, t.location)
+	case weekUnit:
+		// Weeks start on mondays
+		monday := day - (int(tm.Weekday())+6)%7
+		return time.Date(year, month, monday, 0, 0, 0, 0, t.location)
+	case monthUnit:
+		return time.Date(year, (month-1)/time.Month(count)*time.Month(count)+1, 1, 0, 0, 0, 0, t.location)
+	default:
+		return time.Date(year/count*count, time.January, 1, 0, 0, 0, 0, t.location)
+	}
+}
+
+// advance steps a time forward by one calendar step, keeping alignment
+func (t *calendarTicker) advance(tm time.Time) time.Time {
+	tm = tm.In(t.location)
+	year, month, day := tm.Date()
+	hour, minute, second := tm.Clock()
+	count := t.step.count
+
+	switch t.step.unit {
+	case secondUnit:
+		second += count
+	case minuteUnit:
+		minute += count
+	case hourUnit:
+		hour += count
+	case dayUnit:
+		// Day counting restarts at the start of each month when aligning
+		day += count
+	case weekUnit:
+		day += 7 * count
+	case monthUnit:
+		month += time.Month(count)
+	default:
+		year += count
+	}
+	next := t.align(time.Date(year, month, day, hour, minute, second, 0, t.location))
+	if !next.After(tm) {
+		// Wall clock times around daylight saving changes can be ambiguous,
+		// fall back to stepping by the approximate length of the interval.
+		length := calendarUnitSeconds[t.step.unit] * float64(count)
+		next = tm.Add(time.Duration(length) * time.Second)
+	}
+	return next
+}
+
 // ValueTicker returns tick labels by converting floats using strconv.FormatFloat
 func (m *Margaid) ValueTicker(style byte, precision int, base int) Ticker {
 	return &valueTicker{
@@ -100,6 +324,20 @@ func (t *valueTicker) start(axis Axis, _ *Series, steps int) float64 {
 		return startValue
 	}
 
+	if t.projection == SymLog {
+		// Ticks at zero and at whole powers of the base
+		// outside the linear region, with the smallest one as step.
+		threshold := t.m.thresholds[axis]
+		t.step = math.Pow(floatBase, math.Ceil(math.Log(threshold)/math.Log(floatBase)))
+		if minmax.min < 0 {
+			startValue = -math.Max(t.step, t.decade(-minmax.min, math.Ceil))
+		}
+		for startValue < minmax.min {
+			startValue, _ = t.next(startValue)
+		}
+		return startValue
+	}
+
 	t.step = 0
 	startValue = math.Pow(floatBase, math.Round(math.Log(minmax.min)/math.Log(floatBase)))
 	for startValue < minmax.min {
@@ -113,6 +351,19 @@ func (t *valueTicker) next(previous float64) (float64, bool) {
 		return previous + t.step, true
 	}
 
+	if t.projection == SymLog {
+		switch {
+		case previous < -t.step:
+			return -t.decade(-previous, math.Round) / float64(t.base), true
+		case previous < 0:
+			return 0, true
+		case previous < t.step:
+			return t.step, true
+		default:
+			return t.decade(previous, math.Round) * float64(t.base), true
+		}
+	}
+
 	floatBase := float64(t.base)
 	log := math.Log(previous) / math.Log(floatBase)
 	if log < 0 {
@@ -127,6 +378,13 @@ func (t *valueTicker) next(previous float64) (float64, bool) {
 	return next, true
 }
 
+// decade returns the power of the ticker base closest to a positive value,
+// using the rounding function to round the exponent.
+func (t *valueTicker) decade(value float64, round func(float64) float64) float64 {
+	floatBase := float64(t.base)
+	return math.Pow(floatBase, round(math.Log(value)/math.Log(floatBase)))
+}
+
 // LabeledTicker places tick marks and labels for all values
 // of a series. The labels are provided by the labeler function.
 func (m *Margaid) LabeledTicker(labeler func(float64) string) Ticker {
@@ -142,7 +400,7 @@ type labeledTicker struct {
 }
 
 func (t *labeledTicker) label(value float64) string {
-	return svg.EncodeText(t.labeler(value), svg.HAlignMiddle)
+	return t.labeler(value)
 }
 
 func (t *labeledTicker) start(axis Axis, series *Series, _ int) float64 {
@@ -1,6 +1,9 @@
 package margaid
 
 import (
+	"math"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -180,3 +183,189 @@ func TestValueTickerSimpleRange(t *testing.T) {
 	x.Equal(count, 11)
 	x.Assert(more)
 }
+
+type byteTicks struct {
+	step float64
+}
+
+func (b *byteTicks) Label(value float64) string {
+	return strconv.Itoa(int(value/1024)) + " KiB"
+}
+
+func (b *byteTicks) Start(_ Axis, _ *Series, min, max float64, steps int) float64 {
+	b.step = 1024
+	for (max-min)/b.step > float64(steps) {
+		b.step *= 2
+	}
+	return math.Ceil(min/b.step) * b.step
+}
+
+func (b *byteTicks) Next(previous float64) (float64, bool) {
+	return previous + b.step, true
+}
+
+func TestCustomTicker(t *testing.T) {
+	x := xt.X(t)
+
+	max := 8192.0
+	m := New(100, 100, WithRange(YAxis, 1000, max))
+	ticker := m.CustomTicker(&byteTicks{})
+
+	step := ticker.start(YAxis, NewSeries(), 10)
+	x.Equal(step, 1024.0)
+
+	var labels []string
+	more := true
+	for ; step <= max && more; step, more = ticker.next(step) {
+		labels = append(labels, ticker.label(step))
+	}
+
+	x.Equal(len(labels), 8)
+	x.Equal(labels[0], "1 KiB")
+	x.Equal(labels[7], "8 KiB")
+}
+
+func TestCalendarTickerWeek(t *testing.T) {
+	x := xt.X(t)
+
+	min := SecondsFromTime(time.Date(2020, time.September, 4, 9, 10, 0, 0, time.UTC))
+	max := SecondsFromTime(time.Date(2020, time.September, 11, 9, 10, 0, 0, time.UTC))
+
+	m := New(100, 100, WithRange(XAxis, min, max))
+	ticker := m.CalendarTicker(time.UTC)
+
+	step := ticker.start(XAxis, NewSeries(), 10)
+
+	var ticks []time.Time
+	more := true
+	for ; step <= max && more; step, more = ticker.next(step) {
+		ticks = append(ticks, TimeFromSeconds(step).In(time.UTC))
+	}
+
+	// One tick at midnight each day
+	x.Equal(len(ticks), 7)
+	x.Equal(ticks[0], time.Date(2020, time.September, 5, 0, 0, 0, 0, time.UTC))
+	x.Equal(ticks[6], time.Date(2020, time.September, 11, 0, 0, 0, 0, time.UTC))
+}
+
+func TestCalendarTickerMonths(t *testing.T) {
+	x := xt.X(t)
+
+	min := SecondsFromTime(time.Date(2019, time.November, 20, 0, 0, 0, 0, time.UTC))
+	max := SecondsFromTime(time.Date(2020, time.June, 20, 0, 0, 0, 0, time.UTC))
+
+	m := New(100, 100, WithRange(XAxis, min, max))
+	ticker := m.CalendarTicker(time.UTC)
+
+	step := ticker.start(XAxis, NewSeries(), 10)
+	x.Equal(TimeFromSeconds(step).In(time.UTC), time.Date(2019, time.December, 1, 0, 0, 0, 0, time.UTC))
+
+	var labels []string
+	more := true
+	for ; step <= max && more; step, more = ticker.next(step) {
+		labels = append(labels, ticker.label(step))
+	}
+
+	x.Equal(len(labels), 7)
+	x.Assert(strings.Contains(labels[0], "2019"))
+	x.Equal(labels[1], "Jan\n2020")
+	x.Equal(labels[2], "Feb")
+}
+
+func TestCalendarTickerDayChange(t *testing.T) {
+	x := xt.X(t)
+
+	min := SecondsFromTime(time.Date(2020, time.September, 4, 18, 0, 0, 0, time.UTC))
+	max := SecondsFromTime(time.Date(2020, time.September, 5, 6, 0, 0, 0, time.UTC))
+
+	m := New(100, 100, WithRange(XAxis, min, max))
+	ticker := m.CalendarTicker(time.UTC)
+
+	step := ticker.start(XAxis, NewSeries(), 6)
+
+	var labels []string
+	more := true
+	for ; step <= max && more; step, more = ticker.next(step) {
+		labels = append(labels, ticker.label(step))
+	}
+
+	// Two hour steps, with dates at the start and at midnight
+	x.Equal(len(labels), 7)
+	x.Assert(strings.Contains(labels[0], "Sep 4"))
+	x.Equal(labels[1], "20:00")
+	x.Assert(strings.Contains(labels[3], "Sep 5"))
+}
+
+func TestCalendarTickerLabelOrder(t *testing.T) {
+	x := xt.X(t)
+
+	min := SecondsFromTime(time.Date(2020, time.September, 4, 18, 0, 0, 0, time.UTC))
+	max := SecondsFromTime(time.Date(2020, time.September, 5, 6, 0, 0, 0, time.UTC))
+
+	m := New(100, 100, WithRange(XAxis, min, max))
+	ticker := m.CalendarTicker(time.UTC)
+	ticker.start(XAxis, NewSeries(), 6)
+
+	// Labels do not depend on which ticks were labeled before
+	midnight := SecondsFromTime(time.Date(2020, time.September, 5, 0, 0, 0, 0, time.UTC))
+	x.Equal(ticker.label(midnight), "00:00\nSep 5")
+	x.Equal(ticker.label(midnight), "00:00\nSep 5")
+	x.Equal(ticker.label(midnight+7200), "02:00")
+}
+
+func TestCalendarTickerWeeksNewYear(t *testing.T) {
+	x := xt.X(t)
+
+	min := SecondsFromTime(time.Date(2019, time.December, 1, 0, 0, 0, 0, time.UTC))
+	max := SecondsFromTime(time.Date(2020, time.February, 1, 0, 0, 0, 0, time.UTC))
+
+	m := New(100, 100, WithRange(XAxis, min, max))
+	ticker := m.CalendarTicker(time.UTC)
+
+	step := ticker.start(XAxis, NewSeries(), 10)
+
+	var labels []string
+	more := true
+	for ; step <= max && more; step, more = ticker.next(step) {
+		labels = append(labels, ticker.label(step))
+	}
+
+	x.Equal(labels[0], "Dec 2\n2019")
+	x.Equal(labels[1], "Dec 9")
+	x.Equal(labels[5], "Jan 6\n2020")
+	x.Equal(labels[6], "Jan 13")
+}
+
+func TestTimeTickerLocation(t *testing.T) {
+	x := xt.X(t)
+
+	location := time.FixedZone("UTC+2", 2*3600)
+	m := New(100, 100, WithLocation(location))
+	ticker := m.TimeTicker("15:04")
+
+	value := SecondsFromTime(time.Date(2020, time.September, 4, 9, 10, 0, 0, time.UTC))
+	x.Equal(ticker.label(value), "11:10")
+}
+
+func TestValueTickerSymLog(t *testing.T) {
+	x := xt.X(t)
+
+	max := 500.0
+	m := New(100, 100, WithProjection(YAxis, SymLog), WithRange(YAxis, -200, max))
+	ticker := m.ValueTicker('f', 0, 10)
+
+	step := ticker.start(YAxis, NewSeries(), 10)
+
+	var ticks []float64
+	more := true
+	for ; step <= max && more; step, more = ticker.next(step) {
+		ticks = append(ticks, step)
+	}
+
+	// Zero and the powers of ten on both sides
+	x.Equal(len(ticks), 7)
+	x.Equal(ticks[0], -100.0)
+	x.Equal(ticks[2], -1.0)
+	x.Equal(ticks[3], 0.0)
+	x.Equal(ticks[6], 100.0)
+}